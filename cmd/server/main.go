@@ -1,12 +1,20 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 
 	"github.com/palestine-nights/backend/pkg/api"
 	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/importer"
+	"github.com/palestine-nights/backend/pkg/notify"
+	"github.com/palestine-nights/backend/pkg/scheduler"
 	"github.com/palestine-nights/backend/pkg/tools"
 )
 
@@ -22,8 +30,80 @@ func initializeDB() *sqlx.DB {
 	return db.Initialize(connectionString)
 }
 
+func startScheduler(DB *sqlx.DB, server *api.Server) *scheduler.Scheduler {
+	jobs := scheduler.New(DB)
+
+	config := scheduler.ConfigFromEnv()
+
+	// Reservation states are changed by server, so events and webhooks are published.
+	for _, job := range scheduler.ReservationJobs(DB, notify.FromEnv(), server, config) {
+		jobs.Add(job)
+	}
+
+	for _, job := range scheduler.WebhookJobs(server.Webhooks, config) {
+		jobs.Add(job)
+	}
+
+	for _, job := range scheduler.MenuJobs(DB, config) {
+		jobs.Add(job)
+	}
+
+	jobs.Start()
+
+	return jobs
+}
+
+// Imports rows from file: "server import <tables|categories|menu> <file.csv|file.json>".
+// Format is detected by file extension. Exits with non-zero code, when nothing was imported.
+func importFile(DB *sqlx.DB, args []string) {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: server import <tables|categories|menu> <file.csv|file.json>")
+		os.Exit(2)
+	}
+
+	file, err := os.Open(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(args[1])), ".")
+
+	report, err := importer.Import(DB, args[0], format, file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if !report.Imported {
+		os.Exit(1)
+	}
+}
+
 func main() {
 	DB := initializeDB()
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importFile(DB, os.Args[2:])
+		return
+	}
+	db.VerificationTTL = tools.GetEnvDuration("VERIFICATION_TTL", db.VerificationTTL)
+	db.Location = tools.GetEnvLocation("TIMEZONE", db.Location)
+
 	server := api.GetServer(DB)
-	server.ListenAndServe()
+	jobs := startScheduler(DB, server)
+
+	err := server.ListenAndServe()
+
+	// Running jobs are finished after server is stopped.
+	jobs.Stop()
+
+	if err != nil {
+		log.Fatal(err)
+	}
 }
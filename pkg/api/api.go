@@ -1,33 +1,62 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql" // Import SQL driver.
 	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+	"github.com/palestine-nights/backend/pkg/locale"
+	"github.com/palestine-nights/backend/pkg/money"
+	"github.com/palestine-nights/backend/pkg/notify"
+	"github.com/palestine-nights/backend/pkg/payment"
+	"github.com/palestine-nights/backend/pkg/report"
+	"github.com/palestine-nights/backend/pkg/storage"
+	"github.com/palestine-nights/backend/pkg/tools"
+	"github.com/palestine-nights/backend/pkg/webhook"
 )
 
-// GenericError error model.
-//
-// swagger:model
-type GenericError struct {
-	// Error massage.
-	Error string `json:"error"`
-}
-
 // Server is composition of router and DB instances.
 // swagger:ignore
 type Server struct {
-	Router *gin.Engine
-	DB     *sqlx.DB
-	DBConn *sql.Conn
+	Router   *gin.Engine
+	DB       *sqlx.DB
+	DBConn   *sql.Conn
+	Notifier notify.Notifier
+	Webhooks *webhook.Dispatcher
+	Broker   *events.Broker
+	Payments payment.Provider
+	// Automatic booking restriction for guests with no-shows.
+	NoShowPolicy db.NoShowPolicy
+	// Secret to sign reservation access tokens.
+	TokenSecret []byte
+	// Token to access calendar feed without authorization header.
+	CalendarToken string
+	// Opening hours to calculate table utilization in reports.
+	OpeningHours report.OpeningHours
+	// Languages of menu content.
+	Locales locale.Config
+	// Storage of uploaded images.
+	Storage storage.Storage
+	// Limit of uploaded image size in bytes.
+	MaxImageSize int64
 }
 
 // GetServer returns server instance.
 func GetServer(DB *sqlx.DB) *Server {
-	router := gin.Default()
+	router := gin.New()
+
+	// Access token of event stream is passed in query, so it is hidden from request log.
+	router.Use(hideAccessToken, gin.Logger(), restoreAccessToken, gin.Recovery())
 
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
@@ -36,22 +65,98 @@ func GetServer(DB *sqlx.DB) *Server {
 	config.AddAllowHeaders("Authorization")
 	router.Use(cors.New(config))
 
-	server := Server{Router: router, DB: DB}
+	server := Server{
+		Router:        router,
+		DB:            DB,
+		Notifier:      notify.FromEnv(),
+		Webhooks:      webhook.NewDispatcher(DB),
+		Broker:        events.NewBroker(eventsHistorySize),
+		Payments:      payment.FromEnv(),
+		NoShowPolicy:  noShowPolicyFromEnv(),
+		TokenSecret:   []byte(tools.GetEnv("RESERVATION_TOKEN_SECRET", "")),
+		CalendarToken: tools.GetEnv("CALENDAR_FEED_TOKEN", ""),
+		OpeningHours:  openingHoursFromEnv(),
+		Locales:       locale.NewConfig(tools.GetEnv("DEFAULT_LANGUAGE", "en"), tools.GetEnv("LANGUAGES", "en,ar")),
+		Storage:       storage.FromEnv(),
+		MaxImageSize:  tools.GetEnvInt("MAX_IMAGE_SIZE", defaultMaxImageSize),
+	}
 
 	server.initializeRouter()
 
 	return &server
 }
 
-// ListenAndServe server.
-func (server *Server) ListenAndServe() {
-	server.Router.Run()
+func noShowPolicyFromEnv() db.NoShowPolicy {
+	depositPerGuest, err := money.Parse(tools.GetEnv("NO_SHOW_DEPOSIT_PER_GUEST", "5"))
+
+	if err != nil {
+		depositPerGuest = money.FromFloat(5)
+	}
+
+	return db.NoShowPolicy{
+		Limit:           tools.GetEnvInt("NO_SHOW_LIMIT", 3),
+		Window:          tools.GetEnvDuration("NO_SHOW_WINDOW", 90*24*time.Hour),
+		Action:          db.RestrictionAction(tools.GetEnv("NO_SHOW_ACTION", string(db.RestrictionReject))),
+		DepositPerGuest: depositPerGuest,
+	}
+}
+
+func openingHoursFromEnv() report.OpeningHours {
+	hours, err := report.ParseOpeningHours(tools.GetEnv("OPENING_HOURS", defaultOpeningHours))
+
+	if err != nil {
+		hours, _ = report.ParseOpeningHours(defaultOpeningHours)
+	}
+
+	return hours
+}
+
+// Time to finish active requests after shutdown signal.
+const shutdownTimeout = 10 * time.Second
+
+// ListenAndServe serves requests on PORT until interrupt or terminate signal is received,
+// then stops accepting new connections and waits for active requests to finish.
+func (server *Server) ListenAndServe() error {
+	httpServer := &http.Server{
+		Addr:    ":" + tools.GetEnv("PORT", "8080"),
+		Handler: server.Router,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- httpServer.ListenAndServe() }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-signals:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return httpServer.Shutdown(ctx)
 }
 
 func (server *Server) initializeRouter() {
 
 	server.Router.StaticFile("/", "./html/home.html")
 
+	// Uploaded images are served by the server itself, when they are stored locally.
+	if local, ok := server.Storage.(storage.LocalStorage); ok {
+		server.Router.Static(local.URLPrefix, local.Dir)
+	}
+
+	server.Router.NoRoute(func(c *gin.Context) {
+		respondError(c, errNotFound("Route could not be found"))
+	})
+
+	server.Router.GET("/config", server.getConfig)
+	server.Router.GET("/events", EventSourceAuthMiddleware, server.streamEvents)
+
 	tablesRouter := server.Router.Group("/tables")
 	{
 		tablesRouter.GET("", server.listTables)
@@ -67,14 +172,46 @@ func (server *Server) initializeRouter() {
 
 	reservationsRouter := server.Router.Group("/reservations")
 	{
-		reservationsRouter.GET("", server.getReservations)
-		reservationsRouter.GET("/:id", server.getReservation)
+		reservationsRouter.GET("", AuthMiddleware, server.getReservations)
+		reservationsRouter.GET("/:id", server.routeReservation)
 		reservationsRouter.POST("", server.postReservation)
+		reservationsRouter.POST("/validate", server.validateReservation)
+		// Router doesn't allow "/:id/verify" next to static "/approve/:id" and "/cancel/:id",
+		// so verification follows the same "/<action>/:id" form.
+		reservationsRouter.POST("/verify/:id", server.verifyReservation)
+		reservationsRouter.POST("/verify/:id/resend", server.resendVerification)
+		reservationsRouter.POST("/deposit/:id", server.payDeposit)
 
 		reservationsRouter.Use(AuthMiddleware)
 		{
 			reservationsRouter.POST("/approve/:id", server.approveReservation)
 			reservationsRouter.POST("/cancel/:id", server.cancelReservation)
+			reservationsRouter.POST("/seat/:id", server.seatReservation)
+			reservationsRouter.PUT("/:id", server.putReservation)
+		}
+	}
+
+	privateEventsRouter := server.Router.Group("/private-events")
+	{
+		privateEventsRouter.Use(AuthMiddleware)
+		{
+			privateEventsRouter.GET("", server.listPrivateEvents)
+			privateEventsRouter.GET("/:id", server.getPrivateEvent)
+			privateEventsRouter.POST("", server.postPrivateEvent)
+			privateEventsRouter.PUT("/:id", server.putPrivateEvent)
+			privateEventsRouter.DELETE("/:id", server.deletePrivateEvent)
+		}
+	}
+
+	seriesRouter := server.Router.Group("/series")
+	{
+		seriesRouter.Use(AuthMiddleware)
+		{
+			seriesRouter.GET("", server.listSeries)
+			seriesRouter.GET("/:id", server.getSeries)
+			seriesRouter.POST("", server.postSeries)
+			seriesRouter.PUT("/:id", server.putSeries)
+			seriesRouter.DELETE("/:id", server.deleteSeries)
 		}
 	}
 
@@ -88,6 +225,48 @@ func (server *Server) initializeRouter() {
 			menuRouter.POST("", server.postMenuItem)
 			menuRouter.PUT("/:id", server.putMenuItem)
 			menuRouter.DELETE("/:id", server.deleteMenuItem)
+			menuRouter.POST("/:id/image", server.uploadMenuItemImage)
+			menuRouter.GET("/:id/prices", server.listMenuItemPrices)
+			menuRouter.POST("/:id/prices", server.postMenuItemPrice)
+			menuRouter.DELETE("/:id/prices/:price_id", server.deleteMenuItemPrice)
+			menuRouter.GET("/:id/translations", server.listMenuItemTranslations)
+			menuRouter.PUT("/:id/translations/:lang", server.putMenuItemTranslation)
+			menuRouter.DELETE("/:id/translations/:lang", server.deleteMenuItemTranslation)
+		}
+	}
+
+	dietaryTagsRouter := server.Router.Group("/dietary-tags")
+	{
+		dietaryTagsRouter.GET("", server.listDietaryTags)
+
+		dietaryTagsRouter.Use(AuthMiddleware)
+		{
+			dietaryTagsRouter.POST("", server.postDietaryTag)
+			dietaryTagsRouter.PUT("/:id", server.putDietaryTag)
+			dietaryTagsRouter.DELETE("/:id", server.deleteDietaryTag)
+		}
+	}
+
+	optionGroupsRouter := server.Router.Group("/option-groups")
+	{
+		optionGroupsRouter.Use(AuthMiddleware)
+		{
+			optionGroupsRouter.GET("", server.listOptionGroups)
+			optionGroupsRouter.GET("/:id", server.getOptionGroup)
+			optionGroupsRouter.POST("", server.postOptionGroup)
+			optionGroupsRouter.PUT("/:id", server.putOptionGroup)
+			optionGroupsRouter.DELETE("/:id", server.deleteOptionGroup)
+		}
+	}
+
+	optionsRouter := server.Router.Group("/options")
+	{
+		optionsRouter.Use(AuthMiddleware)
+		{
+			optionsRouter.GET("/:id", server.getMenuOption)
+			optionsRouter.POST("", server.postMenuOption)
+			optionsRouter.PUT("/:id", server.putMenuOption)
+			optionsRouter.DELETE("/:id", server.deleteMenuOption)
 		}
 	}
 
@@ -99,7 +278,87 @@ func (server *Server) initializeRouter() {
 		categoriesRouter.Use(AuthMiddleware)
 		{
 			categoriesRouter.POST("", server.postCategory)
-			categoriesRouter.PUT("/:id", server.updateCategory)
+			categoriesRouter.PUT("/:id", staticRoute("id", "order", server.reorderCategories, server.updateCategory))
+			categoriesRouter.DELETE("/:id", server.deleteCategory)
+			categoriesRouter.GET("/:category_id/translations", server.listCategoryTranslations)
+			categoriesRouter.PUT("/:id/translations/:lang", server.putCategoryTranslation)
+			categoriesRouter.DELETE("/:id/translations/:lang", server.deleteCategoryTranslation)
+		}
+	}
+
+	guestsRouter := server.Router.Group("/guests")
+	{
+		guestsRouter.Use(AuthMiddleware)
+		{
+			guestsRouter.GET("", server.listGuests)
+			guestsRouter.GET("/:id", server.getGuest)
+			guestsRouter.PUT("/:id", server.putGuest)
+			guestsRouter.POST("/merge/:id", server.mergeGuest)
+		}
+	}
+
+	blocklistRouter := server.Router.Group("/blocklist")
+	{
+		blocklistRouter.Use(AuthMiddleware)
+		{
+			blocklistRouter.GET("", server.listBlockEntries)
+			blocklistRouter.POST("", server.postBlockEntry)
+			blocklistRouter.PUT("/:id", server.putBlockEntry)
+			blocklistRouter.DELETE("/:id", server.deleteBlockEntry)
+		}
+	}
+
+	depositRulesRouter := server.Router.Group("/deposit-rules")
+	{
+		depositRulesRouter.Use(AuthMiddleware)
+		{
+			depositRulesRouter.GET("", server.listDepositRules)
+			depositRulesRouter.POST("", server.postDepositRule)
+			depositRulesRouter.PUT("/:id", server.putDepositRule)
+			depositRulesRouter.DELETE("/:id", server.deleteDepositRule)
+		}
+	}
+
+	importRouter := server.Router.Group("/import")
+	{
+		importRouter.Use(AuthMiddleware)
+		{
+			importRouter.POST("/:kind", server.importRows)
+		}
+	}
+
+	reportsRouter := server.Router.Group("/reports")
+	{
+		reportsRouter.Use(AuthMiddleware)
+		{
+			reportsRouter.GET("/reservations", server.reservationsReport)
+			reportsRouter.GET("/utilization", server.utilizationReport)
+		}
+	}
+
+	webhooksRouter := server.Router.Group("/webhooks")
+	{
+		webhooksRouter.Use(AuthMiddleware)
+		{
+			webhooksRouter.GET("", server.listWebhooks)
+			webhooksRouter.GET("/:id", server.getWebhook)
+			webhooksRouter.GET("/:id/deliveries", server.listWebhookDeliveries)
+			webhooksRouter.POST("", server.postWebhook)
+			webhooksRouter.PUT("/:id", server.putWebhook)
+			webhooksRouter.DELETE("/:id", server.deleteWebhook)
+		}
+	}
+}
+
+// Returns handler of route with parameter, which passes requests with specified parameter value
+// to handler of static route. Router could not register static route next to parameter,
+// e.g. PUT /categories/order and PUT /categories/:id.
+func staticRoute(param, value string, static, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param(param) == value {
+			static(c)
+		} else {
+			handler(c)
 		}
 	}
 }
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticRoute(t *testing.T) {
+	router := gin.New()
+
+	reply := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.String(http.StatusOK, name+" "+c.Param("id"))
+		}
+	}
+
+	router.PUT("/categories/:id", staticRoute("id", "order", reply("reorder"), reply("update")))
+
+	tests := map[string]string{
+		"/categories/order": "reorder order",
+		"/categories/2":     "update 2",
+	}
+
+	for path, expected := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, path, nil))
+
+		assert.Equal(t, expected, recorder.Body.String(), path)
+	}
+}
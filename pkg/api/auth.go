@@ -3,7 +3,6 @@ package api
 import (
 	"encoding/base64"
 	"errors"
-	"net/http"
 	"os"
 	"strings"
 )
@@ -36,51 +35,52 @@ func validateToken(token *jwt.Token) (interface{}, error) {
 	return signingKey, nil
 }
 
+// Validates authorization header, returns error if it does not contain admin JWT token.
+func authorize(authorizationHeader string) error {
+	if authorizationHeader == "" {
+		return errors.New("Not Authorized")
+	}
+
+	splittedHeader := strings.Split(authorizationHeader, " ")
+
+	if len(splittedHeader) < 2 {
+		return errors.New("Invalid header content")
+	}
+
+	tokenType := splittedHeader[0]   // Assign token type.
+	tokenString := splittedHeader[1] // Assign token value.
+
+	// Validate JWT token type.
+	// Token type should be equal to "Bearer".
+	if tokenType != "Bearer" {
+		return errors.New("Invalid token type")
+	}
+
+	// Validate JWT token.
+	token, err := jwt.Parse(tokenString, validateToken)
+
+	if err != nil {
+		return err
+	}
+
+	if !token.Valid || token.Claims.(jwt.MapClaims)["role"] != "admin" {
+		return errors.New("Not Authorized")
+	}
+
+	return nil
+}
+
+// isAdmin returns true, when request is authorized with admin JWT token.
+func isAdmin(c *gin.Context) bool {
+	return authorize(c.GetHeader("Authorization")) == nil
+}
+
 // AuthMiddleware is gin middleware, thats validates JWT token.
 func AuthMiddleware(c *gin.Context) {
-	authorizationHeader := c.GetHeader("Authorization")
-
-	if authorizationHeader != "" {
-
-		splittedHeader := strings.Split(authorizationHeader, " ")
-
-		if len(splittedHeader) < 2 {
-			c.JSON(http.StatusUnauthorized, GenericError{Error: "Invalid header content"})
-			c.Abort()
-			return
-		}
-
-		tokenType := splittedHeader[0]   // Assign token type.
-		tokenString := splittedHeader[1] // Assign token value.
-
-		// Validate JWT token type.
-		// Token type should be equal to "Bearer".
-		if tokenType != "Bearer" {
-			c.JSON(http.StatusUnauthorized, GenericError{Error: "Invalid token type"})
-			c.Abort()
-			return
-		}
-
-		// Validate JWT token.
-		token, err := jwt.Parse(tokenString, validateToken)
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, GenericError{Error: err.Error()})
-			c.Abort()
-			return
-		}
-
-		if token.Valid && token.Claims.(jwt.MapClaims)["role"] == "admin" {
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, GenericError{Error: "Not Authorized"})
-			c.Abort()
-			return
-		}
-	} else {
-		c.JSON(http.StatusUnauthorized, GenericError{Error: "Not Authorized"})
-		c.Abort()
+	if err := authorize(c.GetHeader("Authorization")); err != nil {
+		abortWithError(c, errUnauthorized(err.Error()))
 		return
 	}
 
+	c.Next()
 }
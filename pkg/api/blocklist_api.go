@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+/// swagger:route GET /blocklist blocklist listBlockEntries
+/// List all blocked contacts and guests.
+/// Responses:
+///   200: []BlockEntry
+///   500: GenericError
+func (server *Server) listBlockEntries(c *gin.Context) {
+	entries, err := db.BlockEntry.GetAll(db.BlockEntry{}, server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusOK, entries)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route POST /blocklist blocklist postBlockEntry
+/// Blocks contact or guest.
+/// Responses:
+///   201: BlockEntry
+///   400: GenericError
+func (server *Server) postBlockEntry(c *gin.Context) {
+	entry := db.BlockEntry{}
+
+	if err := c.ShouldBindJSON(&entry); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := entry.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	err := entry.Insert(server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusCreated, entry)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /blocklist/{id} blocklist putBlockEntry
+/// Updates block entry.
+/// Responses:
+///   200: BlockEntry
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putBlockEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid block entry ID, must be int"))
+		return
+	}
+
+	entry := db.BlockEntry{}
+
+	if err := c.ShouldBindJSON(&entry); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := entry.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	entry.ID = id
+
+	// Check if ID exists.
+	err = entry.Update(server.DB)
+	if err == nil {
+		c.JSON(http.StatusOK, entry)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Block entry with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route DELETE /blocklist/{id} blocklist deleteBlockEntry
+/// Unblocks contact or guest.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteBlockEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid block entry ID, must be int"))
+		return
+	}
+
+	err = db.BlockEntry.Destroy(db.BlockEntry{}, server.DB, id)
+
+	// Check if ID exists.
+	if err == nil {
+		c.JSON(http.StatusNoContent, nil)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Block entry with id %d could not be found", id)))
+	}
+}
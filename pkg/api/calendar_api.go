@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/calendar"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// Router does not allow static paths next to ":id" wildcard,
+// so calendar and export resources are dispatched by the value of ID parameter.
+func (server *Server) routeReservation(c *gin.Context) {
+	id := c.Param("id")
+
+	switch {
+	case id == "export":
+		if AuthMiddleware(c); !c.IsAborted() {
+			server.exportReservations(c)
+		}
+	case id == "calendar.ics":
+		server.getReservationsCalendar(c)
+	case strings.HasSuffix(id, ".ics"):
+		server.getReservationCalendar(c)
+	default:
+		server.getReservation(c)
+	}
+}
+
+// Returns token, which gives access to reservation without authorization header.
+// Empty token is returned, when secret is not configured.
+func (server *Server) reservationToken(id uint64) string {
+	if len(server.TokenSecret) == 0 {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, server.TokenSecret)
+	mac.Write([]byte(strconv.FormatUint(id, 10)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (server *Server) validReservationToken(id uint64, token string) bool {
+	expected := server.reservationToken(id)
+
+	return expected != "" && hmac.Equal([]byte(expected), []byte(token))
+}
+
+// Converts reservation to calendar event.
+func reservationEvent(reservation db.Reservation, table *db.Table) calendar.Event {
+	event := calendar.Event{
+		UID:     fmt.Sprintf("reservation-%d@palestine-nights", reservation.ID),
+		Summary: fmt.Sprintf("Table reservation for %d guest(s)", reservation.Guests),
+		Description: fmt.Sprintf("Name: %s\nPhone: %s\nEmail: %s",
+			reservation.FullName, reservation.Phone, reservation.Email),
+		Status: calendar.StatusTentative,
+		Start:  reservation.Time,
+		End:    reservation.GetStopTime(),
+	}
+
+	if table != nil {
+		event.Location = fmt.Sprintf("Table %d: %s", table.ID, table.Description)
+	}
+
+	switch reservation.State {
+	case db.StateApproved, db.StateSeated, db.StateCompleted:
+		event.Status = calendar.StatusConfirmed
+	case db.StateCancelled, db.StateNoShow:
+		event.Status = calendar.StatusCancelled
+	}
+
+	return event
+}
+
+func writeCalendar(c *gin.Context, filename string, cal calendar.Calendar) {
+	c.Header("Content-Type", calendar.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	if err := cal.Write(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+/// swagger:route GET /reservations/{id}.ics reservations getReservationCalendar
+/// Returns reservation as iCalendar file.
+/// Requires admin authorization or reservation token in "token" query parameter.
+/// Responses:
+///   200:
+///   400: GenericError
+///   401: GenericError
+///   404: GenericError
+func (server *Server) getReservationCalendar(c *gin.Context) {
+	id, err := strconv.ParseUint(strings.TrimSuffix(c.Param("id"), ".ics"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	if !server.validReservationToken(id, c.Query("token")) && !isAdmin(c) {
+		respondError(c, errUnauthorized("Not Authorized"))
+		return
+	}
+
+	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Reservation with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	// Table is optional for the event, so lookup error is ignored.
+	table, _ := db.Table.Find(db.Table{}, server.DB, reservation.TableID)
+
+	cal := calendar.Calendar{Events: []calendar.Event{reservationEvent(*reservation, table)}}
+
+	writeCalendar(c, fmt.Sprintf("reservation-%d.ics", id), cal)
+}
+
+/// swagger:route GET /reservations/calendar.ics reservations getReservationsCalendar
+/// Returns iCalendar feed of upcoming approved reservations.
+/// Requires admin authorization or feed token in "token" query parameter.
+/// Responses:
+///   200:
+///   401: GenericError
+///   500: GenericError
+func (server *Server) getReservationsCalendar(c *gin.Context) {
+	token := c.Query("token")
+	validToken := server.CalendarToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(server.CalendarToken)) == 1
+
+	if !validToken && !isAdmin(c) {
+		respondError(c, errUnauthorized("Not Authorized"))
+		return
+	}
+
+	reservations, err := db.Reservation.GetUpcoming(db.Reservation{}, server.DB)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	tables, err := db.Table.GetAll(db.Table{}, server.DB)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	tablesByID := make(map[uint64]*db.Table)
+	for i := range *tables {
+		tablesByID[(*tables)[i].ID] = &(*tables)[i]
+	}
+
+	cal := calendar.Calendar{Name: "Reservations"}
+
+	for _, reservation := range *reservations {
+		if reservation.State != db.StateApproved && reservation.State != db.StateSeated {
+			continue
+		}
+
+		cal.Events = append(cal.Events, reservationEvent(reservation, tablesByID[reservation.TableID]))
+	}
+
+	writeCalendar(c, "reservations.ics", cal)
+}
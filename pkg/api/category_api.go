@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 )
@@ -19,7 +20,12 @@ func (server *Server) postCategory(c *gin.Context) {
 	category := db.MenuCategory{}
 
 	if err := c.ShouldBindJSON(&category); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := category.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
@@ -28,7 +34,7 @@ func (server *Server) postCategory(c *gin.Context) {
 	if err == nil {
 		c.JSON(http.StatusOK, category)
 	} else {
-		c.JSON(http.StatusNotFound, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
@@ -41,14 +47,19 @@ func (server *Server) updateCategory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid menu category ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid menu category ID, must be int"))
 		return
 	}
 
 	category := db.MenuCategory{}
 
 	if err := c.ShouldBindJSON(&category); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := category.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
@@ -60,38 +71,135 @@ func (server *Server) updateCategory(c *gin.Context) {
 	if err == nil {
 		c.JSON(http.StatusOK, category)
 	} else {
-		c.JSON(http.StatusNotFound, GenericError{Error: err.Error()})
+		if err == db.ErrCategoryOrderTaken {
+			err = errConflict(err.Error())
+		}
+
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu category with id %d could not be found", id)))
 	}
 }
 
 /// swagger:route GET /categories/{category_id} menu listMenuByCategory
 /// List menu items with specified category.
+/// Items are filtered by availability with "at" and "all" query parameters
+/// and by "tags" and "exclude_allergens" query parameters, same as menu.
+/// Content is translated to language from "lang" query parameter or Accept-Language header.
 /// Responses:
 ///   200: []MenuItem
+///   400: GenericError
 ///   500: GenericError
 func (server *Server) listMenuItemsByCategory(c *gin.Context) {
 	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
 
+	if err != nil {
+		respondError(c, errInvalidParameter("category_id", "Invalid category ID, must be int"))
+		return
+	}
+
 	menu, err := db.MenuItem.GetByCategory(db.MenuItem{}, server.DB, categoryID)
 
+	if err == nil {
+		*menu, err = server.availableMenu(c, *menu)
+	}
+
+	if err == nil {
+		*menu = menuFilterFromQuery(c).Apply(*menu)
+		err = server.translateMenu(c, *menu)
+	}
+
 	if err == nil {
 		c.JSON(http.StatusOK, menu)
 	} else {
-		c.JSON(http.StatusInternalServerError, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
 /// swagger:route GET /categories menu getAllCategories
 /// List menu categories.
+/// Names are translated to language from "lang" query parameter or Accept-Language header.
 /// Responses:
 /// 400: []MenuCategory
 /// 404: GenericError
 func (server *Server) getAllCategories(c *gin.Context) {
 	categories, err := db.MenuCategory.GetAll(db.MenuCategory{}, server.DB)
 
+	if err == nil {
+		err = server.translateCategories(c, *categories)
+	}
+
+	if err == nil {
+		c.JSON(http.StatusOK, categories)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /categories/order menu reorderCategories
+/// Reorders all categories in one transaction. Request body is list of IDs of every category in new order.
+/// Responses:
+///   200: []MenuCategory
+///   400: GenericError
+func (server *Server) reorderCategories(c *gin.Context) {
+	ids := make([]uint64, 0)
+
+	if err := c.ShouldBindJSON(&ids); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := db.MenuCategory.Reorder(db.MenuCategory{}, server.DB, ids); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	categories, err := db.MenuCategory.GetAll(db.MenuCategory{}, server.DB)
+
 	if err == nil {
 		c.JSON(http.StatusOK, categories)
 	} else {
-		c.JSON(http.StatusNotFound, GenericError{Error: err.Error()})
+		respondError(c, err)
+	}
+}
+
+/// swagger:route DELETE /categories/{id} menu deleteCategory
+/// Deletes menu category. Its menu items are moved to category from "move_items_to" query parameter,
+/// category with menu items could not be deleted without it. Sub-categories are moved to parent of deleted category.
+/// Responses:
+///   204:
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+func (server *Server) deleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu category ID, must be int"))
+		return
+	}
+
+	var moveItemsTo *uint64
+
+	if value := c.Query("move_items_to"); value != "" {
+		categoryID, err := strconv.ParseUint(value, 10, 64)
+
+		if err != nil {
+			respondError(c, errInvalidParameter("move_items_to", "Invalid menu category ID, must be int"))
+			return
+		}
+
+		moveItemsTo = &categoryID
 	}
+
+	err = db.MenuCategory.Destroy(db.MenuCategory{}, server.DB, id, moveItemsTo)
+
+	if err == db.ErrCategoryNotEmpty {
+		err = errConflict("Category has menu items, move them to other category with move_items_to query parameter")
+	}
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu category with id %d could not be found", id)))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
 }
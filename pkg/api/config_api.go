@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/money"
+)
+
+// Config model for settings, which clients need to display prices and content.
+//
+// swagger:model
+type Config struct {
+	// Currency of all prices and deposits.
+	Currency money.Currency `json:"currency"`
+	// Language of content, stored in menu items and categories themselves.
+	DefaultLanguage string `json:"default_language"`
+	// Languages, content could be translated to, including default one.
+	Languages []string `json:"languages"`
+}
+
+/// swagger:route GET /config config getConfig
+/// Returns currency of prices and languages of content.
+/// Responses:
+///   200: Config
+func (server *Server) getConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, Config{
+		Currency:        money.Default,
+		DefaultLanguage: server.Locales.Default,
+		Languages:       server.Locales.Supported,
+	})
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/locale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfig(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/config", nil)
+
+	server := &Server{Locales: locale.NewConfig("en", "en,ar")}
+	server.getConfig(c)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t,
+		`{"currency": {"code": "BHD", "decimals": 3}, "default_language": "en", "languages": ["en", "ar"]}`,
+		recorder.Body.String())
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// Returns deposit, required for reservation, when it matches any deposit rule
+// or booking is restricted with deposit requirement. Returns nil, when deposit is not required.
+func (server *Server) depositFor(reservation *db.Reservation, restriction *db.Restriction) (*db.Deposit, error) {
+	amount, err := db.DepositRule.RequiredDeposit(db.DepositRule{}, server.DB, reservation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reason := ""
+
+	if restriction != nil && restriction.Action == db.RestrictionDeposit {
+		reason = restriction.Reason
+
+		if restrictionAmount := server.NoShowPolicy.DepositPerGuest.Mul(reservation.Guests); restrictionAmount > amount {
+			amount = restrictionAmount
+		}
+	}
+
+	if amount == 0 {
+		return nil, nil
+	}
+
+	return &db.Deposit{Amount: amount, Status: db.DepositRequired, Reason: reason}, nil
+}
+
+// Loads deposit of reservation, if it exists.
+func (server *Server) loadDeposit(reservation *db.Reservation) error {
+	deposit, err := db.Deposit.FindByReservation(db.Deposit{}, server.DB, reservation.ID)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	reservation.Deposit = deposit
+
+	return nil
+}
+
+// Error of deposit refund, when reservation is already cancelled.
+type refundError struct {
+	err error
+}
+
+func (err *refundError) Error() string {
+	return fmt.Sprintf("Deposit could not be refunded: %s", err.err)
+}
+
+// Returns paid deposit of cancelled reservation to the client.
+func (server *Server) refundDeposit(reservation *db.Reservation) error {
+	if err := server.loadDeposit(reservation); err != nil || reservation.Deposit == nil {
+		return err
+	}
+
+	deposit := reservation.Deposit
+
+	if deposit.Status != db.DepositPaid {
+		return nil
+	}
+
+	if server.Payments == nil {
+		return errors.New("Payment provider is not configured")
+	}
+
+	if err := server.Payments.Refund(*deposit); err != nil {
+		return err
+	}
+
+	deposit.Status = db.DepositRefunded
+
+	return deposit.Update(server.DB)
+}
+
+/// swagger:route POST /reservations/deposit/{id} reservations payDeposit
+/// Pay deposit of reservation, which is not cancelled or finished.
+/// Responses:
+///   200: Deposit
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+///   503: GenericError
+func (server *Server) payDeposit(c *gin.Context) {
+	if server.Payments == nil {
+		respondError(c, newError(http.StatusServiceUnavailable, CodeUnavailable, "Deposit payments are not configured"))
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	deposit, err := db.Deposit.FindByReservation(db.Deposit{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Deposit for reservation with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if reservation.State != db.StateCreated && reservation.State != db.StateApproved {
+		errorMsg := fmt.Sprintf("Deposit of reservation in state %s could not be paid", reservation.State)
+		respondError(c, errConflict(errorMsg))
+		return
+	}
+
+	if deposit.Status != db.DepositRequired {
+		errorMsg := fmt.Sprintf("Deposit could not be paid, it is already %s", deposit.Status)
+		respondError(c, errConflict(errorMsg))
+		return
+	}
+
+	if err := deposit.Claim(server.DB); err != nil {
+		if err == db.ErrDepositClaimed {
+			err = errConflict(err.Error())
+		}
+
+		respondError(c, err)
+		return
+	}
+
+	reference, status, err := server.Payments.Charge(*deposit)
+
+	if err != nil {
+		// Deposit could be paid again after failed payment.
+		if releaseErr := deposit.Release(server.DB); releaseErr != nil {
+			c.Error(releaseErr)
+		}
+
+		respondError(c, newError(http.StatusPaymentRequired, CodePaymentFailed, err.Error()))
+		return
+	}
+
+	deposit.ProviderRef = reference
+	deposit.Status = status
+
+	if err := deposit.Update(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deposit)
+}
+
+/// swagger:route GET /deposit-rules deposits listDepositRules
+/// List all deposit rules.
+/// Responses:
+///   200: []DepositRule
+///   500: GenericError
+func (server *Server) listDepositRules(c *gin.Context) {
+	rules, err := db.DepositRule.GetAll(db.DepositRule{}, server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusOK, rules)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route POST /deposit-rules deposits postDepositRule
+/// Creates deposit rule.
+/// Responses:
+///   201: DepositRule
+///   400: GenericError
+func (server *Server) postDepositRule(c *gin.Context) {
+	rule := db.DepositRule{}
+
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := rule.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	err := rule.Insert(server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusCreated, rule)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /deposit-rules/{id} deposits putDepositRule
+/// Updates deposit rule.
+/// Responses:
+///   200: DepositRule
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putDepositRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid deposit rule ID, must be int"))
+		return
+	}
+
+	rule := db.DepositRule{}
+
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := rule.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	rule.ID = id
+
+	// Check if ID exists.
+	err = rule.Update(server.DB)
+	if err == nil {
+		c.JSON(http.StatusOK, rule)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Deposit rule with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route DELETE /deposit-rules/{id} deposits deleteDepositRule
+/// Deletes deposit rule.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteDepositRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid deposit rule ID, must be int"))
+		return
+	}
+
+	err = db.DepositRule.Destroy(db.DepositRule{}, server.DB, id)
+
+	// Check if ID exists.
+	if err == nil {
+		c.JSON(http.StatusNoContent, nil)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Deposit rule with id %d could not be found", id)))
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// Counts charges and declines them, when error is set.
+type testProvider struct {
+	charges int
+	err     error
+}
+
+func (provider *testProvider) Charge(deposit db.Deposit) (string, db.DepositStatus, error) {
+	provider.charges++
+	return "test_ref", db.DepositPaid, provider.err
+}
+
+func (provider *testProvider) Refund(deposit db.Deposit) error {
+	return nil
+}
+
+func TestPayDeposit(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   db.State
+		claimed bool
+		err     error
+		status  int
+		charges int
+	}{
+		{"paid", db.StateApproved, true, nil, http.StatusOK, 1},
+		{"cancelled reservation", db.StateCancelled, true, nil, http.StatusConflict, 0},
+		{"no-show reservation", db.StateNoShow, true, nil, http.StatusConflict, 0},
+		{"concurrent payment", db.StateCreated, false, nil, http.StatusConflict, 0},
+		{"declined", db.StateApproved, true, errors.New("Card declined"), http.StatusPaymentRequired, 1},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectQuery("^SELECT (.+) FROM deposits WHERE reservation_id = \\?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reservation_id", "amount", "status"}).
+				AddRow(3, 7, "10.000", db.DepositRequired))
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(7, test.state))
+
+		if test.state == db.StateApproved || test.state == db.StateCreated {
+			rows := int64(0)
+			if test.claimed {
+				rows = 1
+			}
+
+			mock.ExpectExec("^UPDATE deposits SET status = \\? WHERE id = \\? AND status = \\?").
+				WithArgs(db.DepositPending, 3, db.DepositRequired).
+				WillReturnResult(sqlmock.NewResult(0, rows))
+		}
+
+		if test.status == http.StatusOK {
+			mock.ExpectExec("^UPDATE deposits SET status=").WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		if test.err != nil {
+			// Claim is released, so deposit could be paid again.
+			mock.ExpectExec("^UPDATE deposits SET status = \\? WHERE id = \\? AND status = \\?").
+				WithArgs(db.DepositRequired, 3, db.DepositPending).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		provider := testProvider{err: test.err}
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodPost, "/reservations/deposit/7", nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+		server := Server{DB: DB, Payments: &provider}
+		server.payDeposit(c)
+
+		assert.Equal(t, test.status, recorder.Code, test.name)
+		assert.Equal(t, test.charges, provider.charges, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+/// swagger:route GET /dietary-tags menu listDietaryTags
+/// List allergen and dietary tags, which menu items could be filtered by.
+/// Responses:
+///   200: []DietaryTag
+///   500: GenericError
+func (server *Server) listDietaryTags(c *gin.Context) {
+	tags, err := db.DietaryTag.GetAll(db.DietaryTag{}, server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusOK, tags)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route POST /dietary-tags menu postDietaryTag
+/// Creates dietary tag.
+/// Responses:
+///   201: DietaryTag
+///   400: GenericError
+///   409: GenericError
+func (server *Server) postDietaryTag(c *gin.Context) {
+	tag := db.DietaryTag{}
+
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := tag.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	err := tag.Insert(server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusCreated, tag)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /dietary-tags/{id} menu putDietaryTag
+/// Updates dietary tag.
+/// Responses:
+///   200: DietaryTag
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+func (server *Server) putDietaryTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid dietary tag ID, must be int"))
+		return
+	}
+
+	tag := db.DietaryTag{}
+
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := tag.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	tag.ID = id
+
+	// Check if ID exists.
+	err = tag.Update(server.DB)
+	if err == nil {
+		c.JSON(http.StatusOK, tag)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Dietary tag with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route DELETE /dietary-tags/{id} menu deleteDietaryTag
+/// Deletes dietary tag and removes it from menu items.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteDietaryTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid dietary tag ID, must be int"))
+		return
+	}
+
+	err = db.DietaryTag.Destroy(db.DietaryTag{}, server.DB, id)
+
+	// Check if ID exists.
+	if err == nil {
+		c.JSON(http.StatusNoContent, nil)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Dietary tag with id %d could not be found", id)))
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/money"
+)
+
+// ErrorCode is stable machine-readable code of API error.
+// swagger:strfmt errorCode
+type ErrorCode string
+
+const (
+	// CodeInvalidPayload is returned, when request body could not be parsed.
+	CodeInvalidPayload ErrorCode = "invalid_payload"
+	// CodeInvalidParameter is returned for invalid path or query parameter.
+	CodeInvalidParameter ErrorCode = "invalid_parameter"
+	// CodeValidationFailed is returned, when request is well-formed, but fields are invalid.
+	CodeValidationFailed ErrorCode = "validation_failed"
+	// CodeUnauthorized is returned for missing or invalid credentials.
+	CodeUnauthorized ErrorCode = "unauthorized"
+	// CodeForbidden is returned, when action is not allowed for the client.
+	CodeForbidden ErrorCode = "forbidden"
+	// CodeNotFound is returned, when resource does not exist.
+	CodeNotFound ErrorCode = "not_found"
+	// CodeConflict is returned, when action conflicts with current state of resource.
+	CodeConflict ErrorCode = "conflict"
+	// CodePaymentFailed is returned, when payment provider has declined the charge.
+	CodePaymentFailed ErrorCode = "payment_failed"
+	// CodeTooManyRequests is returned, when client has exceeded number of attempts.
+	CodeTooManyRequests ErrorCode = "too_many_requests"
+	// CodeUnavailable is returned, when feature is not configured on the server.
+	CodeUnavailable ErrorCode = "unavailable"
+	// CodeInternal is returned for unexpected server errors, details are only logged.
+	CodeInternal ErrorCode = "internal_error"
+)
+
+// MySQL error numbers, which are mapped to client errors.
+const (
+	mysqlDuplicateEntry      = 1062
+	mysqlRowIsReferenced     = 1451
+	mysqlNoReferencedRow     = 1452
+	mysqlRowIsReferencedLong = 1217
+	mysqlNoReferencedRowLong = 1216
+)
+
+// GenericError error model.
+//
+// swagger:model
+type GenericError struct {
+	// Stable code of the error.
+	Code ErrorCode `json:"code"`
+	// Error massage.
+	Error string `json:"error"`
+	// Errors of every invalid field.
+	Details []db.FieldError `json:"details,omitempty"`
+}
+
+// APIError is error with HTTP status, which is returned to the client as is.
+type APIError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Details []db.FieldError
+}
+
+func (err *APIError) Error() string {
+	return err.Message
+}
+
+func newError(status int, code ErrorCode, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// Returns error for request body, which could not be parsed, with details of invalid field if known.
+func errInvalidPayload(err error) *APIError {
+	apiErr := newError(http.StatusBadRequest, CodeInvalidPayload, "Invalid request payload")
+
+	switch err := err.(type) {
+	case *json.UnmarshalTypeError:
+		apiErr.Details = []db.FieldError{{
+			Field: err.Field,
+			Error: fmt.Sprintf("Invalid value, should be %s", err.Type.String()),
+		}}
+	case *json.SyntaxError:
+		apiErr.Message = "Invalid request payload, JSON is malformed"
+	case *money.ParseError:
+		apiErr.Message = "Invalid request payload, " + err.Error()
+	}
+
+	return apiErr
+}
+
+// Returns error for invalid path or query parameter.
+func errInvalidParameter(parameter, message string) *APIError {
+	apiErr := newError(http.StatusBadRequest, CodeInvalidParameter, message)
+	apiErr.Details = []db.FieldError{{Field: parameter, Error: message}}
+
+	return apiErr
+}
+
+// Returns error for invalid query parameters.
+func errInvalidQuery(err error) *APIError {
+	return newError(http.StatusBadRequest, CodeInvalidParameter, err.Error())
+}
+
+// Returns error for invalid fields, message of the first one is used as error message.
+func errValidation(fieldErrors []db.FieldError) *APIError {
+	apiErr := newError(http.StatusBadRequest, CodeValidationFailed, "Validation failed")
+	apiErr.Details = fieldErrors
+
+	if len(fieldErrors) != 0 {
+		apiErr.Message = fieldErrors[0].Error
+	}
+
+	return apiErr
+}
+
+// Returns validation error with message of model validation
+// and errors of every invalid field, when they are known.
+func errInvalid(err error) *APIError {
+	if fieldErrors, ok := err.(db.ValidationErrors); ok {
+		return errValidation(fieldErrors)
+	}
+
+	return newError(http.StatusBadRequest, CodeValidationFailed, err.Error())
+}
+
+func errNotFound(message string) *APIError {
+	return newError(http.StatusNotFound, CodeNotFound, message)
+}
+
+func errConflict(message string) *APIError {
+	return newError(http.StatusConflict, CodeConflict, message)
+}
+
+func errForbidden(message string) *APIError {
+	return newError(http.StatusForbidden, CodeForbidden, message)
+}
+
+func errUnauthorized(message string) *APIError {
+	return newError(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Replaces missing row error with not found error with specified message.
+func notFoundError(err error, message string) error {
+	if err == sql.ErrNoRows {
+		return errNotFound(message)
+	}
+
+	return err
+}
+
+// Converts error to API error: known DB errors are mapped to client errors,
+// other errors are hidden from the client.
+func toAPIError(err error) *APIError {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr
+	}
+
+	if err == sql.ErrNoRows {
+		return errNotFound("Resource could not be found")
+	}
+
+	if fieldErrors, ok := err.(db.ValidationErrors); ok {
+		return errValidation(fieldErrors)
+	}
+
+	if _, ok := err.(*db.StateTransitionError); ok {
+		return errConflict(err.Error())
+	}
+
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return errConflict("Resource already exists")
+		case mysqlRowIsReferenced, mysqlRowIsReferencedLong:
+			return errConflict("Resource is still referenced by other resources")
+		case mysqlNoReferencedRow, mysqlNoReferencedRowLong:
+			return newError(http.StatusBadRequest, CodeValidationFailed, "Referenced resource does not exist")
+		}
+	}
+
+	return newError(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// Responds with error, converted to API error. Original error is attached to context to be logged.
+func respondError(c *gin.Context, err error) {
+	apiErr := toAPIError(err)
+
+	if apiErr.Status == http.StatusInternalServerError {
+		c.Error(err)
+	}
+
+	c.JSON(apiErr.Status, GenericError{Code: apiErr.Code, Error: apiErr.Message, Details: apiErr.Details})
+}
+
+// Responds with error and stops execution of other handlers.
+func abortWithError(c *gin.Context, err error) {
+	respondError(c, err)
+	c.Abort()
+}
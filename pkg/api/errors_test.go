@@ -0,0 +1,60 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		{"api error", errConflict("Already paid"), http.StatusConflict, CodeConflict},
+		{"missing row", sql.ErrNoRows, http.StatusNotFound, CodeNotFound},
+		{"duplicate entry", &mysql.MySQLError{Number: 1062}, http.StatusConflict, CodeConflict},
+		{"referenced row", &mysql.MySQLError{Number: 1451}, http.StatusConflict, CodeConflict},
+		{"missing reference", &mysql.MySQLError{Number: 1452}, http.StatusBadRequest, CodeValidationFailed},
+		{"unexpected error", errors.New("connection refused"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			apiErr := toAPIError(test.err)
+
+			assert.Equal(t, test.status, apiErr.Status)
+			assert.Equal(t, test.code, apiErr.Code)
+		})
+	}
+
+	// Unexpected errors should not leak to the client.
+	assert.Equal(t, "Internal server error", toAPIError(errors.New("connection refused")).Message)
+}
+
+func TestErrInvalidPayload(t *testing.T) {
+	reservation := db.Reservation{}
+	err := json.Unmarshal([]byte(`{"guests": "four"}`), &reservation)
+
+	apiErr := errInvalidPayload(err)
+
+	assert.Equal(t, CodeInvalidPayload, apiErr.Code)
+	assert.Equal(t, []db.FieldError{{Field: "guests", Error: "Invalid value, should be int64"}}, apiErr.Details)
+}
+
+func TestNotFoundError(t *testing.T) {
+	assert.Equal(t, errNotFound("Table with id 1 could not be found"), notFoundError(sql.ErrNoRows, "Table with id 1 could not be found"))
+
+	other := errors.New("connection refused")
+	assert.Equal(t, other, notFoundError(other, "Table with id 1 could not be found"))
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"io"
+	"time"
+)
+
+import (
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/events"
+)
+
+// Number of events, kept in memory to resume streams.
+const eventsHistorySize = 1000
+
+// Period between keep-alive comments, which prevent proxies from closing idle streams.
+const eventsKeepAlive = 15 * time.Second
+
+// Publishes event to live stream subscribers and webhooks.
+func (server *Server) publish(event string, data interface{}) {
+	if server.Broker != nil {
+		server.Broker.Publish(event, data)
+	}
+
+	if server.Webhooks != nil {
+		go server.Webhooks.Dispatch(event, data)
+	}
+}
+
+// EventSourceAuthMiddleware allows to pass JWT token in "access_token" query parameter,
+// because browser EventSource API does not support custom headers.
+func EventSourceAuthMiddleware(c *gin.Context) {
+	if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	AuthMiddleware(c)
+}
+
+// Context key of original query, while it is hidden from request log.
+const originalQueryKey = "originalQuery"
+
+// Hides access token from request logger, which is placed between hideAccessToken and restoreAccessToken.
+func hideAccessToken(c *gin.Context) {
+	query := c.Request.URL.Query()
+
+	if query.Get("access_token") == "" {
+		return
+	}
+
+	c.Set(originalQueryKey, c.Request.URL.RawQuery)
+
+	query.Set("access_token", "REDACTED")
+	c.Request.URL.RawQuery = query.Encode()
+}
+
+// Restores query with access token after request logger has read it.
+func restoreAccessToken(c *gin.Context) {
+	if raw, ok := c.Get(originalQueryKey); ok {
+		c.Request.URL.RawQuery = raw.(string)
+	}
+}
+
+/// swagger:route GET /events events streamEvents
+/// Stream of reservation and table changes in Server-Sent Events format.
+/// Send "Last-Event-ID" header to receive events, missed after reconnect.
+/// Responses:
+///   200:
+///   401: GenericError
+func (server *Server) streamEvents(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	subscriber, missed, unsubscribe := server.Broker.Subscribe(lastEventID)
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, event := range missed {
+		renderEvent(c, event)
+	}
+
+	// Missed events are sent right away, not with the next live event.
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-subscriber:
+			if !ok {
+				return false
+			}
+
+			renderEvent(c, event)
+		case <-keepAlive.C:
+			io.WriteString(w, ":keep-alive\n\n")
+		case <-c.Request.Context().Done():
+			return false
+		}
+
+		return true
+	})
+}
+
+func renderEvent(c *gin.Context, event events.Event) {
+	c.Render(-1, sse.Event{
+		Id:    event.ID,
+		Event: event.Type,
+		Data:  event.Data,
+	})
+}
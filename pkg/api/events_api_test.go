@@ -0,0 +1,32 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokenIsHiddenFromLog(t *testing.T) {
+	log := bytes.Buffer{}
+	token := ""
+
+	router := gin.New()
+	router.Use(hideAccessToken, gin.LoggerWithWriter(&log), restoreAccessToken)
+	router.GET("/events", func(c *gin.Context) {
+		token = c.Query("access_token")
+		c.Status(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/events?access_token=secret-jwt&last_event_id=1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, "secret-jwt", token)
+	assert.NotContains(t, log.String(), "secret-jwt")
+	assert.Contains(t, log.String(), "access_token=REDACTED")
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/export"
+)
+
+// Columns of reservations export.
+var exportColumns = []interface{}{
+	"id", "table_id", "state", "time", "duration_minutes", "guests", "full_name", "email", "phone",
+	"occasion", "accessibility_needs", "children", "high_chairs", "notes", "verified_at", "created_at",
+}
+
+func exportRow(reservation *db.Reservation) []interface{} {
+	return []interface{}{
+		reservation.ID,
+		reservation.TableID,
+		string(reservation.State),
+		reservation.Time,
+		int64(reservation.Duration / time.Minute),
+		reservation.Guests,
+		reservation.FullName,
+		reservation.Email,
+		reservation.Phone,
+		string(reservation.Occasion),
+		reservation.AccessibilityNeeds,
+		reservation.Children,
+		reservation.HighChairs,
+		reservation.Notes,
+		reservation.VerifiedAt,
+		reservation.CreatedAt,
+	}
+}
+
+/// swagger:route GET /reservations/export reservations exportReservations
+/// Exports reservations as CSV or Excel file with format=csv|xlsx query parameter.
+/// Reservations are filtered with the same query parameters as listing.
+/// Responses:
+///   200: file
+///   400: GenericError
+///   401: GenericError
+func (server *Server) exportReservations(c *gin.Context) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+
+	if !export.IsValidFormat(format) {
+		respondError(c, errInvalidParameter("format", "Invalid format, should be csv or xlsx"))
+		return
+	}
+
+	filter, err := reservationFilterFromQuery(c)
+
+	if err != nil {
+		respondError(c, errInvalidQuery(err))
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="reservations.%s"`, format))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer)
+
+	if err == nil {
+		err = writer.Write(exportColumns)
+	}
+
+	if err == nil {
+		err = db.Reservation.EachFiltered(db.Reservation{}, server.DB, filter, func(reservation *db.Reservation) error {
+			return writer.Write(exportRow(reservation))
+		})
+	}
+
+	// Headers were already sent, so error could only be logged.
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err != nil {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// GuestMerge is request body to merge duplicate guest profiles.
+//
+// swagger:model
+type GuestMerge struct {
+	// ID of duplicate guest, which is merged and deleted.
+	// required: true
+	SourceID uint64 `json:"source_id"`
+}
+
+/// swagger:route GET /guests guests listGuests
+/// List guests, optionally filtered by "q" query parameter.
+/// Responses:
+///   200: []Guest
+///   500: GenericError
+func (server *Server) listGuests(c *gin.Context) {
+	guests, err := db.Guest.GetAll(db.Guest{}, server.DB, strings.TrimSpace(c.Query("q")))
+
+	if err == nil {
+		c.JSON(http.StatusOK, guests)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route GET /guests/{id} guests getGuest
+/// Returns guest profile with visit history.
+/// Responses:
+///   200: GuestProfile
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getGuest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid guest ID, must be int"))
+		return
+	}
+
+	profile, err := db.Guest.GetProfile(db.Guest{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, profile)
+	} else {
+		errorMsg := fmt.Sprintf("Guest with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+	}
+}
+
+/// swagger:route PUT /guests/{id} guests putGuest
+/// Updates guest contacts, notes and tags.
+/// Responses:
+///   200: Guest
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putGuest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid guest ID, must be int"))
+		return
+	}
+
+	guest := db.Guest{}
+
+	if err := c.ShouldBindJSON(&guest); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	guest.ID = id
+
+	// Check if ID exists, guest is validated on update.
+	err = guest.Update(server.DB)
+	if err == nil {
+		c.JSON(http.StatusOK, guest)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Guest with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route POST /guests/merge/{id} guests mergeGuest
+/// Merges duplicate guest profile into guest with specified ID.
+/// Responses:
+///   200: GuestProfile
+///   400: GenericError
+///   404: GenericError
+func (server *Server) mergeGuest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid guest ID, must be int"))
+		return
+	}
+
+	body := GuestMerge{}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	guest, err := db.Guest.Find(db.Guest{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Guest with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if body.SourceID == id {
+		respondError(c, errValidation([]db.FieldError{{Field: "source_id", Error: "Guest could not be merged with itself"}}))
+		return
+	}
+
+	if err := guest.Merge(server.DB, body.SourceID); err != nil {
+		errorMsg := fmt.Sprintf("Guest with id %d could not be found", body.SourceID)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	profile, err := db.Guest.GetProfile(db.Guest{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, profile)
+	} else {
+		respondError(c, err)
+	}
+}
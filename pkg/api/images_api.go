@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+	"github.com/palestine-nights/backend/pkg/images"
+)
+
+// Maximal width and height of menu image thumbnails.
+const thumbnailSize = 320
+
+// Default limit of uploaded image size.
+const defaultMaxImageSize = 5 << 20
+
+/// swagger:route POST /menu/{id}/image menu uploadMenuItemImage
+/// Uploads JPEG or PNG image of menu item in "image" field of multipart form.
+/// Image is stored with its thumbnail and their URLs are set to menu item, previous files are deleted.
+/// Responses:
+///   200: MenuItem
+///   400: GenericError
+///   404: GenericError
+///   413: GenericError
+func (server *Server) uploadMenuItemImage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	menuItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
+	// Multipart overhead is allowed on top of image size.
+	maxRequestSize := server.MaxImageSize + 1<<10
+	tooLarge := newError(http.StatusRequestEntityTooLarge, CodeInvalidPayload,
+		fmt.Sprintf("Image should not be larger than %d KB", server.MaxImageSize>>10))
+
+	if c.Request.ContentLength > maxRequestSize {
+		respondError(c, tooLarge)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize)
+
+	header, err := c.FormFile("image")
+
+	if err != nil {
+		respondError(c, errInvalidParameter("image", "Image should be uploaded in \"image\" field of multipart form"))
+		return
+	}
+
+	if header.Size > server.MaxImageSize {
+		respondError(c, tooLarge)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	image, err := images.Process(data, thumbnailSize)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("image", err.Error()))
+		return
+	}
+
+	name := make([]byte, 8)
+	if _, err := rand.Read(name); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	key := fmt.Sprintf("menu/%d/%s", id, hex.EncodeToString(name))
+
+	imageURL, err := server.Storage.Put(key+image.Extension, bytes.NewReader(image.Data), image.ContentType)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	thumbnailURL, err := server.Storage.Put(key+"_thumb"+image.Extension, bytes.NewReader(image.Thumbnail), image.ContentType)
+	if err != nil {
+		server.deleteImages(imageURL)
+		respondError(c, err)
+		return
+	}
+
+	oldURLs := []string{menuItem.ImageURL, menuItem.ThumbnailURL}
+
+	if err := menuItem.SetImage(server.DB, imageURL, thumbnailURL); err != nil {
+		server.deleteImages(imageURL, thumbnailURL)
+		respondError(c, err)
+		return
+	}
+
+	server.deleteImages(oldURLs...)
+
+	server.publish(events.MenuItemUpdated, menuItem)
+	c.JSON(http.StatusOK, menuItem)
+}
+
+// Deletes replaced, removed or unused files from storage.
+// Files, which are not kept in the storage, e.g. external URLs, are skipped.
+// Menu item is already saved, so failed deletion is only logged.
+func (server *Server) deleteImages(urls ...string) {
+	for _, url := range urls {
+		key, ok := server.Storage.Key(url)
+
+		if !ok {
+			continue
+		}
+
+		if err := server.Storage.Delete(key); err != nil {
+			log.Printf("[images] could not delete %s: %s", key, err)
+		}
+	}
+}
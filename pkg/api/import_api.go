@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/importer"
+)
+
+/// swagger:route POST /import/{kind} import importRows
+/// Imports tables, categories or menu items from CSV or JSON array.
+/// Format is detected from Content-Type header or set with format query parameter.
+/// Rows are imported at once only when every row is valid, otherwise report contains errors of every row.
+/// Responses:
+///   200: Report
+///   400: GenericError
+///   422: Report
+///   500: GenericError
+func (server *Server) importRows(c *gin.Context) {
+	kind := c.Param("kind")
+
+	if !importer.IsValidKind(kind) {
+		respondError(c, errInvalidParameter("kind", "Invalid kind, should be tables, categories or menu"))
+		return
+	}
+
+	format := c.Query("format")
+
+	if format == "" {
+		format = importer.FormatJSON
+
+		if strings.HasPrefix(c.ContentType(), "text/csv") {
+			format = importer.FormatCSV
+		}
+	}
+
+	if !importer.IsValidFormat(format) {
+		respondError(c, errInvalidParameter("format", "Invalid format, should be csv or json"))
+		return
+	}
+
+	batch, rowErrors, err := importer.Parse(kind, format, c.Request.Body)
+
+	if err != nil {
+		respondError(c, newError(http.StatusBadRequest, CodeInvalidPayload, err.Error()))
+		return
+	}
+
+	report, err := importer.Apply(server.DB, kind, batch, rowErrors)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !report.Imported {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -4,30 +4,93 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
 )
 
+// Parses menu filter from comma-separated "tags" and "exclude_allergens" query parameters.
+func menuFilterFromQuery(c *gin.Context) db.MenuFilter {
+	codes := func(param string) []string {
+		result := make([]string, 0)
+
+		for _, value := range c.QueryArray(param) {
+			for _, code := range strings.Split(value, ",") {
+				if code = strings.ToLower(strings.TrimSpace(code)); code != "" {
+					result = append(result, code)
+				}
+			}
+		}
+
+		return result
+	}
+
+	return db.MenuFilter{
+		Tags:             codes("tags"),
+		ExcludeAllergens: codes("exclude_allergens"),
+	}
+}
+
+// Returns menu items, which could be ordered at time from "at" query parameter in RFC 3339 format,
+// current time by default. All items are returned, when "all=true" query parameter is specified.
+func (server *Server) availableMenu(c *gin.Context, menuItems []db.MenuItem) ([]db.MenuItem, error) {
+	if c.Query("all") == "true" {
+		return menuItems, nil
+	}
+
+	at := time.Now()
+
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		if err != nil {
+			return nil, errInvalidParameter("at", "Invalid at time, should be in RFC 3339 format")
+		}
+
+		at = parsed
+	}
+
+	return db.AvailableMenu(server.DB, menuItems, at)
+}
+
 /// swagger:route GET /menu menu listMenu
-/// List all menu items.
+/// List menu items, which could be ordered now, with their option groups, options and dietary tags.
+/// Availability at other time is previewed with "at" query parameter, e.g. ?at=2019-11-25T08:00:00Z,
+/// all items including inactive ones are returned with ?all=true.
+/// Items are filtered by comma-separated "tags", which every item should have,
+/// and "exclude_allergens", which items should not contain, e.g. ?exclude_allergens=nuts&tags=vegan.
+/// Content is translated to language from "lang" query parameter or Accept-Language header.
 /// Responses:
 ///   200: []MenuItem
+///   400: GenericError
 ///   500: GenericError
 func (server *Server) listMenu(c *gin.Context) {
 	menu, err := db.MenuItem.GetAll(db.MenuItem{}, server.DB)
 
+	if err == nil {
+		*menu, err = server.availableMenu(c, *menu)
+	}
+
+	if err == nil {
+		*menu = menuFilterFromQuery(c).Apply(*menu)
+		err = server.translateMenu(c, *menu)
+	}
+
 	if err == nil {
 		c.JSON(http.StatusOK, menu)
 	} else {
-		c.JSON(http.StatusInternalServerError, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
 /// swagger:route GET /menu/{id} menu getMenuItem
-/// Returns menu item.
+/// Returns menu item with its option groups and options.
+/// Content is translated to language from "lang" query parameter or Accept-Language header.
 /// Responses:
 ///   200: MenuItem
 ///   404: GenericError
@@ -35,18 +98,26 @@ func (server *Server) getMenuItem(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, GenericError{Error: "Invalid menu item ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
 		return
 	}
 
 	menuItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id)
 
-	if err == nil {
-		c.JSON(http.StatusOK, menuItem)
-	} else {
+	if err != nil {
 		errorMsg := fmt.Sprintf("Menu item with id %d could not be found", id)
-		c.JSON(http.StatusNotFound, GenericError{Error: errorMsg})
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	menuItems := []db.MenuItem{*menuItem}
+
+	if err := server.translateMenu(c, menuItems); err != nil {
+		respondError(c, err)
+		return
 	}
+
+	c.JSON(http.StatusOK, menuItems[0])
 }
 
 /// swagger:route POST /menu menu postMenuItem
@@ -57,33 +128,28 @@ func (server *Server) getMenuItem(c *gin.Context) {
 func (server *Server) postMenuItem(c *gin.Context) {
 	menuItem := db.MenuItem{}
 
-	if err := c.BindJSON(&menuItem); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
-		return
-	}
-
-	// Validations
-	if len(menuItem.Name) == 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Name should not be empty"})
+	if err := c.ShouldBindJSON(&menuItem); err != nil {
+		respondError(c, errInvalidPayload(err))
 		return
 	}
 
-	if menuItem.Price <= 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Price should be more than 0"})
+	if err := menuItem.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
 	err := menuItem.Insert(server.DB)
 
 	if err == nil {
+		server.publish(events.MenuItemCreated, menuItem)
 		c.JSON(http.StatusCreated, menuItem)
 	} else {
-		c.JSON(http.StatusBadRequest, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
 /// swagger:route PUT /menu/{id} menu putMenuItem
-/// Update menu item.
+/// Update menu item. Stored files of previous image are deleted, when image URL is changed.
 /// Responses:
 ///   200: MenuItem
 ///   400: GenericError
@@ -91,41 +157,46 @@ func (server *Server) putMenuItem(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid menu item ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
 		return
 	}
 
 	menuItem := db.MenuItem{}
 
 	if err := c.ShouldBindJSON(&menuItem); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+		respondError(c, errInvalidPayload(err))
 		return
 	}
 
-	// Validations
-	if len(menuItem.Name) == 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Name should not be empty"})
-		return
-	}
-
-	if menuItem.Price <= 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Price should be more than 0"})
+	if err := menuItem.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
 	menuItem.ID = id
 
 	// Check if ID exists.
+	existingItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id)
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
 	err = menuItem.Update(server.DB)
 	if err == nil {
+		if menuItem.ImageURL != existingItem.ImageURL {
+			server.deleteImages(existingItem.ImageURL, existingItem.ThumbnailURL)
+		}
+
+		server.publish(events.MenuItemUpdated, menuItem)
 		c.JSON(http.StatusOK, menuItem)
 	} else {
-		c.JSON(http.StatusNotFound, GenericError{Error: err.Error()})
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
 	}
 }
 
 /// swagger:route DELETE /menu/{id} menu deleteMenuItem
-/// Delete menu item.
+/// Delete menu item with its stored image files.
 /// Responses:
 ///   204:
 ///   404: GenericError
@@ -133,16 +204,22 @@ func (server *Server) deleteMenuItem(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid menu item ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
 		return
 	}
 
-	err = db.MenuItem.Destroy(db.MenuItem{}, server.DB, id)
+	menuItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id)
 
 	// Check if ID exists.
 	if err == nil {
+		err = db.MenuItem.Destroy(db.MenuItem{}, server.DB, id)
+	}
+
+	if err == nil {
+		server.deleteImages(menuItem.ImageURL, menuItem.ThumbnailURL)
+		server.publish(events.MenuItemDeleted, gin.H{"id": id})
 		c.JSON(http.StatusNoContent, nil)
 	} else {
-		c.JSON(http.StatusNotFound, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/locale"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// Keeps files in memory and records deleted keys.
+type testStorage struct {
+	deleted []string
+}
+
+func (storage *testStorage) Put(key string, r io.Reader, contentType string) (string, error) {
+	return "/uploads/" + key, nil
+}
+
+func (storage *testStorage) Delete(key string) error {
+	storage.deleted = append(storage.deleted, key)
+	return nil
+}
+
+func (storage *testStorage) Key(url string) (string, bool) {
+	if !strings.HasPrefix(url, "/uploads/") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(url, "/uploads/"), true
+}
+
+// Expects queries of menu item with its details.
+func expectMenuItem(mock sqlmock.Sqlmock, imageURL, thumbnailURL string) {
+	mock.ExpectQuery("^SELECT (.+) FROM menu WHERE id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "image_url", "thumbnail_url"}).
+			AddRow(1, "Shakshuka", imageURL, thumbnailURL))
+	mock.ExpectQuery("^SELECT (.+) FROM option_groups").WillReturnRows(sqlmock.NewRows([]string{"id", "menu_item_id"}))
+	mock.ExpectQuery("^SELECT (.+) FROM menu_tags").WillReturnRows(sqlmock.NewRows([]string{"menu_item_id", "code"}))
+	mock.ExpectQuery("^SELECT (.+) FROM availability_windows").WillReturnRows(sqlmock.NewRows([]string{"id", "menu_item_id"}))
+}
+
+func TestGetMenuItemTranslated(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		header   string
+		expected string
+	}{
+		{name: "query parameter", query: "?lang=ar", expected: "شكشوكة"},
+		{name: "Accept-Language header", header: "ar", expected: "شكشوكة"},
+		{name: "default language", expected: "Shakshuka"},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectQuery("^SELECT (.+) FROM menu WHERE id = \\?").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "Shakshuka", "Eggs in tomato sauce"))
+		mock.ExpectQuery("^SELECT (.+) FROM option_groups").WillReturnRows(sqlmock.NewRows([]string{"id", "menu_item_id"}))
+		mock.ExpectQuery("^SELECT (.+) FROM menu_tags").WillReturnRows(sqlmock.NewRows([]string{"menu_item_id", "code"}))
+		mock.ExpectQuery("^SELECT (.+) FROM availability_windows").WillReturnRows(sqlmock.NewRows([]string{"id", "menu_item_id"}))
+
+		if test.expected != "Shakshuka" {
+			mock.ExpectQuery("^SELECT menu_item_id, name, description FROM menu_translations").
+				WithArgs("ar", 1).
+				WillReturnRows(sqlmock.NewRows([]string{"menu_item_id", "name", "description"}).AddRow(1, "شكشوكة", ""))
+		}
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodGet, "/menu/1"+test.query, nil)
+		c.Request.Header.Set("Accept-Language", test.header)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+		server := &Server{DB: DB, Locales: locale.NewConfig("en", "en,ar")}
+		server.getMenuItem(c)
+
+		menuItem := db.MenuItem{}
+		json.Unmarshal(recorder.Body.Bytes(), &menuItem)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.name)
+		assert.Equal(t, test.expected, menuItem.Name, test.name)
+		// Description is not translated, so it is kept in default language.
+		assert.Equal(t, "Eggs in tomato sauce", menuItem.Description, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
+
+func TestDeleteMenuItemDeletesImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		imageURL string
+		expected []string
+	}{
+		{name: "stored image", imageURL: "/uploads/menu/1/a.jpg", expected: []string{"menu/1/a.jpg", "menu/1/a_thumb.jpg"}},
+		{name: "external image", imageURL: "https://example.com/a.jpg", expected: nil},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		thumbnailURL := strings.Replace(test.imageURL, ".jpg", "_thumb.jpg", 1)
+
+		expectMenuItem(mock, test.imageURL, thumbnailURL)
+		expectMenuItem(mock, test.imageURL, thumbnailURL)
+		mock.ExpectExec("^DELETE FROM menu WHERE id = \\?").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/menu/1", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+		storage := &testStorage{}
+		server := &Server{DB: DB, Storage: storage}
+		server.deleteMenuItem(c)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code, test.name)
+		assert.Equal(t, test.expected, storage.deleted, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
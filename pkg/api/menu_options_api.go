@@ -0,0 +1,311 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+)
+
+// Publishes menu item with its options, after its option groups were changed.
+func (server *Server) publishMenuItemUpdate(menuItemID uint64) {
+	if menuItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, menuItemID); err == nil {
+		server.publish(events.MenuItemUpdated, menuItem)
+	}
+}
+
+// Validates option group and that its menu item exists.
+func (server *Server) checkOptionGroup(group *db.OptionGroup) error {
+	if err := group.Validate(); err != nil {
+		return errInvalid(err)
+	}
+
+	if _, err := db.MenuItem.Find(db.MenuItem{}, server.DB, group.MenuItemID); err != nil {
+		errorMsg := fmt.Sprintf("Invalid menu item id %d", group.MenuItemID)
+		return errValidation([]db.FieldError{{Field: "menu_item_id", Error: errorMsg}})
+	}
+
+	return nil
+}
+
+// Validates option and returns its group, when it exists.
+func (server *Server) checkMenuOption(option *db.MenuOption) (*db.OptionGroup, error) {
+	if err := option.Validate(); err != nil {
+		return nil, errInvalid(err)
+	}
+
+	group, err := db.OptionGroup.Find(db.OptionGroup{}, server.DB, option.GroupID)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Invalid option group id %d", option.GroupID)
+		return nil, errValidation([]db.FieldError{{Field: "group_id", Error: errorMsg}})
+	}
+
+	return group, nil
+}
+
+/// swagger:route GET /option-groups options listOptionGroups
+/// List option groups of menu item, specified by "menu_item_id" query parameter.
+/// Responses:
+///   200: []OptionGroup
+///   400: GenericError
+func (server *Server) listOptionGroups(c *gin.Context) {
+	menuItemID, err := strconv.ParseUint(c.Query("menu_item_id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("menu_item_id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	groups, err := db.OptionGroup.GetByMenuItem(db.OptionGroup{}, server.DB, menuItemID)
+
+	if err == nil {
+		c.JSON(http.StatusOK, groups)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route GET /option-groups/{id} options getOptionGroup
+/// Returns option group with its options.
+/// Responses:
+///   200: OptionGroup
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getOptionGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option group ID, must be int"))
+		return
+	}
+
+	group, err := db.OptionGroup.Find(db.OptionGroup{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, group)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option group with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route POST /option-groups options postOptionGroup
+/// Creates option group of menu item.
+/// Responses:
+///   201: OptionGroup
+///   400: GenericError
+func (server *Server) postOptionGroup(c *gin.Context) {
+	group := db.OptionGroup{}
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := server.checkOptionGroup(&group); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := group.Insert(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	server.publishMenuItemUpdate(group.MenuItemID)
+	c.JSON(http.StatusCreated, group)
+}
+
+/// swagger:route PUT /option-groups/{id} options putOptionGroup
+/// Updates option group.
+/// Responses:
+///   200: OptionGroup
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putOptionGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option group ID, must be int"))
+		return
+	}
+
+	group := db.OptionGroup{}
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := server.checkOptionGroup(&group); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	group.ID = id
+
+	// Check if ID exists.
+	if err := group.Update(server.DB); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option group with id %d could not be found", id)))
+		return
+	}
+
+	server.publishMenuItemUpdate(group.MenuItemID)
+	c.JSON(http.StatusOK, group)
+}
+
+/// swagger:route DELETE /option-groups/{id} options deleteOptionGroup
+/// Deletes option group with its options.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteOptionGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option group ID, must be int"))
+		return
+	}
+
+	group, err := db.OptionGroup.Find(db.OptionGroup{}, server.DB, id)
+
+	if err == nil {
+		err = db.OptionGroup.Destroy(db.OptionGroup{}, server.DB, id)
+	}
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option group with id %d could not be found", id)))
+		return
+	}
+
+	server.publishMenuItemUpdate(group.MenuItemID)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+/// swagger:route GET /options/{id} options getMenuOption
+/// Returns option.
+/// Responses:
+///   200: MenuOption
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getMenuOption(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option ID, must be int"))
+		return
+	}
+
+	option, err := db.MenuOption.Find(db.MenuOption{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, option)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route POST /options options postMenuOption
+/// Creates option in option group.
+/// Responses:
+///   201: MenuOption
+///   400: GenericError
+func (server *Server) postMenuOption(c *gin.Context) {
+	option := db.MenuOption{}
+
+	if err := c.ShouldBindJSON(&option); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	group, err := server.checkMenuOption(&option)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := option.Insert(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	server.publishMenuItemUpdate(group.MenuItemID)
+	c.JSON(http.StatusCreated, option)
+}
+
+/// swagger:route PUT /options/{id} options putMenuOption
+/// Updates option.
+/// Responses:
+///   200: MenuOption
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putMenuOption(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option ID, must be int"))
+		return
+	}
+
+	option := db.MenuOption{}
+
+	if err := c.ShouldBindJSON(&option); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	group, err := server.checkMenuOption(&option)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	option.ID = id
+
+	// Check if ID exists.
+	if err := option.Update(server.DB); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option with id %d could not be found", id)))
+		return
+	}
+
+	server.publishMenuItemUpdate(group.MenuItemID)
+	c.JSON(http.StatusOK, option)
+}
+
+/// swagger:route DELETE /options/{id} options deleteMenuOption
+/// Deletes option.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteMenuOption(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid option ID, must be int"))
+		return
+	}
+
+	option, err := db.MenuOption.Find(db.MenuOption{}, server.DB, id)
+
+	if err == nil {
+		err = db.MenuOption.Destroy(db.MenuOption{}, server.DB, id)
+	}
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Option with id %d could not be found", id)))
+		return
+	}
+
+	if group, err := db.OptionGroup.Find(db.OptionGroup{}, server.DB, option.GroupID); err == nil {
+		server.publishMenuItemUpdate(group.MenuItemID)
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
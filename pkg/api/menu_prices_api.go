@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+/// swagger:route GET /menu/{id}/prices menu listMenuItemPrices
+/// List price history of menu item with scheduled price changes, ordered by effective time.
+/// Responses:
+///   200: []MenuPrice
+///   400: GenericError
+///   404: GenericError
+func (server *Server) listMenuItemPrices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	if _, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
+	prices, err := db.MenuPrice.GetByMenuItem(db.MenuPrice{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, prices)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route POST /menu/{id}/prices menu postMenuItemPrice
+/// Schedules price change of menu item, which is applied at effective time.
+/// Responses:
+///   201: MenuPrice
+///   400: GenericError
+///   404: GenericError
+func (server *Server) postMenuItemPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	menuPrice := db.MenuPrice{}
+
+	if err := c.ShouldBindJSON(&menuPrice); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := menuPrice.Validate(); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	if _, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
+	menuPrice.MenuItemID = id
+
+	if err := menuPrice.Insert(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, menuPrice)
+}
+
+/// swagger:route DELETE /menu/{id}/prices/{price_id} menu deleteMenuItemPrice
+/// Cancels scheduled price change of menu item. Applied prices could not be deleted.
+/// Responses:
+///   204:
+///   400: GenericError
+///   404: GenericError
+func (server *Server) deleteMenuItemPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	priceID, err := strconv.ParseUint(c.Param("price_id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("price_id", "Invalid price ID, must be int"))
+		return
+	}
+
+	if err := db.MenuPrice.DestroyScheduled(db.MenuPrice{}, server.DB, id, priceID); err != nil {
+		errorMsg := fmt.Sprintf("Scheduled price change with id %d of menu item with id %d could not be found", priceID, id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
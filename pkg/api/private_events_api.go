@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// Validates private event, its tables and that no active reservations hold blocked tables.
+func (server *Server) checkPrivateEvent(event *db.PrivateEvent) error {
+	if err := event.Validate(); err != nil {
+		return errInvalid(err)
+	}
+
+	for _, tableID := range event.TableIDs {
+		if _, err := db.Table.Find(db.Table{}, server.DB, tableID); err != nil {
+			return errValidation([]db.FieldError{{Field: "table_ids", Error: fmt.Sprintf("Invalid table id %d", tableID)}})
+		}
+	}
+
+	reservations, err := event.GetReservations(server.DB)
+
+	if err != nil {
+		return err
+	}
+
+	if len(*reservations) != 0 {
+		return errConflict(fmt.Sprintf(
+			"Event overlaps with %d active reservations, they should be moved or cancelled first", len(*reservations)))
+	}
+
+	return nil
+}
+
+/// swagger:route GET /private-events privateEvents listPrivateEvents
+/// List all private events and buyouts.
+/// Responses:
+///   200: []PrivateEvent
+///   500: GenericError
+func (server *Server) listPrivateEvents(c *gin.Context) {
+	events, err := db.PrivateEvent.GetAll(db.PrivateEvent{}, server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusOK, events)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route GET /private-events/{id} privateEvents getPrivateEvent
+/// Returns private event.
+/// Responses:
+///   200: PrivateEvent
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getPrivateEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid event ID, must be int"))
+		return
+	}
+
+	event, err := db.PrivateEvent.Find(db.PrivateEvent{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, event)
+	} else {
+		errorMsg := fmt.Sprintf("Event with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+	}
+}
+
+/// swagger:route POST /private-events privateEvents postPrivateEvent
+/// Creates private event, which blocks chosen tables or whole venue.
+/// Responses:
+///   201: PrivateEvent
+///   400: GenericError
+///   409: GenericError
+func (server *Server) postPrivateEvent(c *gin.Context) {
+	event := db.PrivateEvent{}
+
+	if err := c.ShouldBindJSON(&event); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := server.checkPrivateEvent(&event); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	err := event.Insert(server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusCreated, event)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /private-events/{id} privateEvents putPrivateEvent
+/// Updates private event.
+/// Responses:
+///   200: PrivateEvent
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+func (server *Server) putPrivateEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid event ID, must be int"))
+		return
+	}
+
+	event := db.PrivateEvent{}
+
+	if err := c.ShouldBindJSON(&event); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	event.ID = id
+
+	if err := server.checkPrivateEvent(&event); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Check if ID exists.
+	err = event.Update(server.DB)
+	if err == nil {
+		c.JSON(http.StatusOK, event)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Event with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route DELETE /private-events/{id} privateEvents deletePrivateEvent
+/// Deletes private event and releases its tables.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deletePrivateEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid event ID, must be int"))
+		return
+	}
+
+	err = db.PrivateEvent.Destroy(db.PrivateEvent{}, server.DB, id)
+
+	// Check if ID exists.
+	if err == nil {
+		c.JSON(http.StatusNoContent, nil)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Event with id %d could not be found", id)))
+	}
+}
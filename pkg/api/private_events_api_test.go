@@ -0,0 +1,65 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestPrivateEventConflictsWithReservations(t *testing.T) {
+	body := `{"name": "Wedding", "contact_name": "Organizer", "contact_phone": "+97336123456",
+		"starts_at": "2019-12-20T18:00:00Z", "ends_at": "2019-12-20T22:00:00Z", "table_ids": [2]}`
+
+	tests := []struct {
+		name    string
+		method  string
+		handler func(server *Server) gin.HandlerFunc
+	}{
+		{"create", http.MethodPost, func(server *Server) gin.HandlerFunc { return server.postPrivateEvent }},
+		{"update", http.MethodPut, func(server *Server) gin.HandlerFunc { return server.putPrivateEvent }},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectQuery("^SELECT (.+) FROM tables WHERE id = \\?").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "places"}).AddRow(2, 4))
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE (.+) AND table_id IN \\(\\?\\)").
+			WithArgs(
+				time.Date(2019, 12, 20, 22, 0, 0, 0, time.UTC),
+				time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC),
+				int64(db.VerificationTTL.Seconds()),
+				2,
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "table_id", "state"}).AddRow(1, 2, db.StateApproved))
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(test.method, "/private-events", bytes.NewBufferString(body))
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+		test.handler(&Server{DB: DB})(c)
+
+		// Event is not saved, while reservations hold its tables.
+		assert.Equal(t, http.StatusConflict, recorder.Code, test.name)
+		assert.Contains(t, recorder.Body.String(), "Event overlaps with 1 active reservations", test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/report"
+)
+
+const defaultOpeningHours = "10:00-23:00"
+
+// Default range of reports is last week.
+const defaultReportRange = 7 * 24 * time.Hour
+
+// Parses report range from "from" and "to" query parameters in RFC 3339 format.
+// Last week until the start of current day in restaurant location is used by default.
+func reportRangeFromQuery(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now().In(db.Location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, db.Location)
+
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		if err != nil {
+			return to, to, errors.New("Invalid to time, should be in RFC 3339 format")
+		}
+
+		to = parsed
+	}
+
+	from := to.Add(-defaultReportRange)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		if err != nil {
+			return from, to, errors.New("Invalid from time, should be in RFC 3339 format")
+		}
+
+		from = parsed
+	}
+
+	if !to.After(from) {
+		return from, to, errors.New("Invalid time range, from should be before to")
+	}
+
+	return from, to, nil
+}
+
+// Writes report as CSV, when format=csv query parameter is specified, or as JSON otherwise.
+func writeReport(c *gin.Context, name string, data interface{}, writeCSV func() error) {
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, data)
+		return
+	}
+
+	c.Header("Content-Type", report.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	if err := writeCSV(); err != nil {
+		c.Error(err)
+	}
+}
+
+/// swagger:route GET /reports/reservations reports reservationsReport
+/// Returns number of reservations, served covers, average party size, no-show rate
+/// and upcoming reservations, which are not served yet,
+/// grouped by day, week, month, state, table or hour in restaurant time zone with group_by query parameter.
+/// Range is set with from and to (RFC 3339), last week by default. CSV is returned with format=csv.
+/// Responses:
+///   200: []Summary
+///   400: GenericError
+///   500: GenericError
+func (server *Server) reservationsReport(c *gin.Context) {
+	group := c.DefaultQuery("group_by", report.GroupDay)
+
+	if !report.IsValidGroup(group) {
+		errorMsg := fmt.Sprintf("Invalid group_by, should be one of %s", strings.Join(report.Groups, ", "))
+		respondError(c, errInvalidParameter("group_by", errorMsg))
+		return
+	}
+
+	from, to, err := reportRangeFromQuery(c)
+
+	if err != nil {
+		respondError(c, errInvalidQuery(err))
+		return
+	}
+
+	reservations, err := db.Reservation.GetFiltered(db.Reservation{}, server.DB, db.ReservationFilter{From: &from, To: &to})
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	summaries := report.Summarize(*reservations, group)
+
+	writeReport(c, "reservations-by-"+group, summaries, func() error {
+		return report.WriteSummaries(c.Writer, summaries)
+	})
+}
+
+/// swagger:route GET /reports/utilization reports utilizationReport
+/// Returns share of opening hours, when every table was booked.
+/// Range is set with from and to (RFC 3339), last week by default. CSV is returned with format=csv.
+/// Responses:
+///   200: []Utilization
+///   400: GenericError
+///   500: GenericError
+func (server *Server) utilizationReport(c *gin.Context) {
+	from, to, err := reportRangeFromQuery(c)
+
+	if err != nil {
+		respondError(c, errInvalidQuery(err))
+		return
+	}
+
+	// Reservations, which started before the range, could still last in it.
+	since := from.Add(-24 * time.Hour)
+
+	reservations, err := db.Reservation.GetFiltered(db.Reservation{}, server.DB, db.ReservationFilter{From: &since, To: &to})
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	tables, err := db.Table.GetAll(db.Table{}, server.DB)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	utilizations := report.Utilize(*reservations, *tables, server.OpeningHours, from, to)
+
+	writeReport(c, "utilization", utilizations, func() error {
+		return report.WriteUtilizations(c.Writer, utilizations)
+	})
+}
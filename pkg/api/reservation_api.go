@@ -1,15 +1,21 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+	"github.com/palestine-nights/backend/pkg/notify"
 )
 
 /* Table Reservations API */
@@ -22,102 +28,480 @@ import (
 func (server *Server) postReservation(c *gin.Context) {
 	reservation := db.Reservation{}
 
-	if err := c.ShouldBindJSON(&reservation); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+	if err := bindReservation(c, &reservation); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	// Set default state "created" after creating.
-	reservation.State = db.StateCreated
+	fieldErrors, err := server.checkReservation(&reservation)
 
-	// Validate, that number of guests is more that 0.
-	if reservation.Guests <= 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid number of guests, should be greater that 0"})
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	// Validate, that duration between 1h to 6h.
-	if reservation.Duration < time.Hour {
-		errorMsg := fmt.Sprintf("Invalid duration time, should be more than %s", time.Hour.String())
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+	if len(fieldErrors) != 0 {
+		respondError(c, errValidation(fieldErrors))
 		return
 	}
-	if reservation.Duration > 6*time.Hour {
-		errorMsg := fmt.Sprintf("Invalid duration time, should be less than %s", time.Hour.String())
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+
+	channel := notify.Channel(reservation.VerificationChannel)
+
+	// Restrictions are checked before guest profile is created for rejected contact.
+	if err := reservation.MatchGuest(server.DB); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	err := reservation.Validate(server.DB)
+	restriction, err := reservation.CheckRestrictions(server.DB, server.NoShowPolicy)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	// Validate, that reservation time is not earlier than current time.
-	if reservation.Time.Before(time.Now()) {
-		errorMsg := fmt.Sprintf("Invalid reservation time")
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+	if restriction != nil && restriction.Action == db.RestrictionReject {
+		respondError(c, errForbidden(restriction.Reason))
 		return
 	}
 
-	// Validate, that table with TableID exists.
-	table, err := db.Table.Find(db.Table{}, server.DB, reservation.TableID)
+	deposit, err := server.depositFor(&reservation, restriction)
+
 	if err != nil {
-		errorMsg := fmt.Sprintf("Invalid table id %d", reservation.TableID)
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+		respondError(c, err)
+		return
+	}
+
+	if err := reservation.Create(server.DB, deposit); err != nil {
+		respondError(c, err)
 		return
 	}
-	// Validate, that number of guests not bigger that table has.
+
+	server.publish(events.ReservationCreated, reservation)
+
+	reservation.VerificationChannel = string(channel)
+	reservation.Token = server.reservationToken(reservation.ID)
+
+	// Reservation is already created, so failed notification doesn't fail the request,
+	// client could request new code.
+	if err := server.sendVerification(&reservation, channel); err != nil {
+		log.Printf("[verification] reservation %d: could not send code: %s", reservation.ID, err)
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// Binds new reservation from request body. Fields, which are set only by server:
+// guest profile, series, state and verification, are reset to their initial values.
+func bindReservation(c *gin.Context, reservation *db.Reservation) error {
+	if err := c.ShouldBindJSON(reservation); err != nil {
+		return errInvalidPayload(err)
+	}
+
+	reservation.ID = 0
+	reservation.GuestID = nil
+	reservation.SeriesID = nil
+	reservation.Detached = false
+	reservation.VerifiedAt = nil
+	reservation.Deposit = nil
+
+	// Set default state "created" after creating.
+	reservation.State = db.StateCreated
+
+	return nil
+}
+
+// Runs all checks of new reservation and returns errors of every failing field.
+// Email is used as verification channel by default.
+func (server *Server) checkReservation(reservation *db.Reservation) ([]db.FieldError, error) {
+	fieldErrors, err := reservation.ValidateFields(server.DB)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors = append(fieldErrors, server.tableErrors(reservation)...)
+
+	// Validate verification channel, email is used by default.
+	if reservation.VerificationChannel == "" {
+		reservation.VerificationChannel = string(notify.ChannelEmail)
+	}
+	channel := notify.Channel(reservation.VerificationChannel)
+	if channel != notify.ChannelEmail && channel != notify.ChannelSMS {
+		fieldErrors = append(fieldErrors, db.FieldError{
+			Field: "verification_channel",
+			Error: "Invalid verification channel, should be email or sms",
+		})
+	}
+
+	return fieldErrors, nil
+}
+
+// Validates, that table of reservation exists and has enough places for guests.
+func (server *Server) tableErrors(reservation *db.Reservation) []db.FieldError {
+	table, err := db.Table.Find(db.Table{}, server.DB, reservation.TableID)
+
+	if err != nil {
+		return []db.FieldError{{Field: "table_id", Error: fmt.Sprintf("Invalid table id %d", reservation.TableID)}}
+	}
+
 	if reservation.Guests > table.Places {
-		errorMsg := fmt.Sprintf("Invalid amount of guests, maximum amount for this table is %d", table.Places)
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+		return []db.FieldError{{
+			Field: "guests",
+			Error: fmt.Sprintf("Invalid amount of guests, maximum amount for this table is %d", table.Places),
+		}}
+	}
+
+	return nil
+}
+
+// ReservationValidation is result of reservation dry run.
+//
+// swagger:model
+type ReservationValidation struct {
+	// Reservation could be created.
+	Valid bool `json:"valid"`
+	// Errors of every failing field.
+	Errors []db.FieldError `json:"errors"`
+}
+
+/// swagger:route POST /reservations/validate reservations validateReservation
+/// Runs all checks of reservation without creating it.
+/// Returns errors of every failing field, e.g. to show availability of the slot before contacts are entered.
+/// Responses:
+///   200: ReservationValidation
+///   400: GenericError
+///   500: GenericError
+func (server *Server) validateReservation(c *gin.Context) {
+	reservation := db.Reservation{}
+
+	if err := bindReservation(c, &reservation); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	fieldErrors, err := server.checkReservation(&reservation)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Restrictions are checked only for valid contacts, guest profile is not created.
+	if !hasFieldError(fieldErrors, "email", "phone") {
+		if err := reservation.MatchGuest(server.DB); err != nil {
+			respondError(c, err)
+			return
+		}
+
+		restriction, err := reservation.CheckRestrictions(server.DB, server.NoShowPolicy)
+
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		if restriction != nil && restriction.Action == db.RestrictionReject {
+			fieldErrors = append(fieldErrors, db.FieldError{Field: "contact", Error: restriction.Reason})
+		}
+	}
+
+	c.JSON(http.StatusOK, ReservationValidation{Valid: len(fieldErrors) == 0, Errors: fieldErrors})
+}
+
+func hasFieldError(fieldErrors []db.FieldError, fields ...string) bool {
+	for _, fieldError := range fieldErrors {
+		for _, field := range fields {
+			if fieldError.Field == field {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Sends one-time code to verify contact of the client.
+func (server *Server) sendVerification(reservation *db.Reservation, channel notify.Channel) error {
+	verification, code, err := db.NewVerification(reservation, string(channel))
+
+	if err != nil {
+		return err
+	}
+
+	if err := verification.Insert(server.DB); err != nil {
+		return err
+	}
+
+	message := notify.Message{
+		Channel: channel,
+		To:      reservation.Email,
+		Subject: "Reservation verification code",
+		Body: fmt.Sprintf("Your verification code is %s. It is valid for %s.",
+			code, db.VerificationTTL.String()),
+	}
+
+	if channel == notify.ChannelSMS {
+		message.To = reservation.Phone
+	}
+
+	return server.Notifier.Notify(message)
+}
+
+// VerificationCode is request body to verify reservation.
+//
+// swagger:model
+type VerificationCode struct {
+	// Code, sent to email or phone.
+	// required: true
+	Code string `json:"code"`
+}
+
+/// swagger:route POST /reservations/verify/{id} reservations verifyReservation
+/// Verify reservation with code, sent to email or phone.
+/// Responses:
+///   200: Reservation
+///   400: GenericError
+///   404: GenericError
+///   429: GenericError
+func (server *Server) verifyReservation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	body := VerificationCode{}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, errInvalidPayload(err))
 		return
 	}
 
-	err = reservation.Insert(server.DB)
+	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
 
-	if err == nil {
+	if err != nil {
+		errorMsg := fmt.Sprintf("Reservation with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if reservation.VerifiedAt != nil {
 		c.JSON(http.StatusOK, reservation)
-	} else {
-		c.JSON(http.StatusConflict, GenericError{Error: err.Error()})
+		return
+	}
+
+	verification, err := db.Verification.FindLast(db.Verification{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, errInvalid(db.ErrVerificationExpired))
+		return
 	}
+
+	switch err := verification.Check(server.DB, body.Code); err {
+	case nil:
+	case db.ErrVerificationAttempts:
+		respondError(c, newError(http.StatusTooManyRequests, CodeTooManyRequests, err.Error()))
+		return
+	case db.ErrVerificationExpired, db.ErrVerificationCode:
+		respondError(c, errInvalid(err))
+		return
+	default:
+		respondError(c, err)
+		return
+	}
+
+	// Unverified reservation releases the table after verification period,
+	// so time could be taken meanwhile, when code was sent again.
+	if err := reservation.CheckTime(server.DB); err != nil {
+		if _, ok := err.(db.ValidationErrors); ok {
+			err = errConflict(err.Error())
+		}
+
+		respondError(c, err)
+		return
+	}
+
+	if err := reservation.MarkVerified(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+/// swagger:route POST /reservations/verify/{id}/resend reservations resendVerification
+/// Sends new verification code to the same channel, e.g. when previous code was not delivered or has expired.
+/// Code could be sent again only after a minute and at most 5 times for one reservation.
+/// Responses:
+///   204:
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+///   429: GenericError
+func (server *Server) resendVerification(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Reservation with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if reservation.VerifiedAt != nil || reservation.State != db.StateCreated {
+		respondError(c, errConflict("Reservation does not need verification"))
+		return
+	}
+
+	switch err := db.Verification.CanResend(db.Verification{}, server.DB, id); err {
+	case nil:
+	case db.ErrVerificationResend:
+		respondError(c, newError(http.StatusTooManyRequests, CodeTooManyRequests, err.Error()))
+		return
+	default:
+		respondError(c, err)
+		return
+	}
+
+	// Code is sent to the same channel as before.
+	channel := notify.ChannelEmail
+
+	if verification, err := db.Verification.FindLast(db.Verification{}, server.DB, id); err == nil {
+		channel = notify.Channel(verification.Channel)
+	} else if err != sql.ErrNoRows {
+		respondError(c, err)
+		return
+	}
+
+	if err := server.sendVerification(reservation, channel); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
 }
 
 /// swagger:route GET /reservations/{id} reservations getReservation
 /// Returns reservation.
+/// Requires admin authorization or reservation token in "token" query parameter.
 /// Responses:
 ///   200: Reservation
+///   400: GenericError
+///   401: GenericError
 ///   404: GenericError
 func (server *Server) getReservation(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid reservation ID, must be integer"})
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	if !server.validReservationToken(id, c.Query("token")) && !isAdmin(c) {
+		respondError(c, errUnauthorized("Not Authorized"))
 		return
 	}
 
 	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
 
-	if err == nil {
-		c.JSON(http.StatusOK, reservation)
-	} else {
+	if err != nil {
 		errorMsg := fmt.Sprintf("Reservation with id %d could not be found", id)
-		c.JSON(http.StatusNotFound, GenericError{Error: errorMsg})
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if err := server.loadDeposit(reservation); err != nil {
+		respondError(c, err)
+		return
 	}
+
+	c.JSON(http.StatusOK, reservation)
 }
 
-/// swagger:route GET /reservations/{id} reservations getReservations
-/// Returns reservation.
+// Parses reservation filter from query parameters:
+// upcoming, from, to (RFC 3339), state, table_id, occasion and comma-separated needs.
+func reservationFilterFromQuery(c *gin.Context) (db.ReservationFilter, error) {
+	filter := db.ReservationFilter{
+		Upcoming: c.Query("upcoming") == "true",
+		State:    db.State(c.Query("state")),
+		Occasion: db.Occasion(c.Query("occasion")),
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		if err != nil {
+			return filter, fmt.Errorf("Invalid %s time, should be in RFC 3339 format", param)
+		}
+
+		if param == "from" {
+			filter.From = &parsed
+		} else {
+			filter.To = &parsed
+		}
+	}
+
+	if tableID := c.Query("table_id"); tableID != "" {
+		id, err := strconv.ParseUint(tableID, 10, 64)
+
+		if err != nil {
+			return filter, errors.New("Invalid table ID, must be integer")
+		}
+
+		filter.TableID = id
+	}
+
+	if filter.Occasion != "" && !filter.Occasion.IsValid() {
+		return filter, errors.New("Invalid occasion")
+	}
+
+	if needs := c.Query("needs"); needs != "" {
+		for _, need := range strings.Split(needs, ",") {
+			if !db.IsValidNeed(need) {
+				return filter, fmt.Errorf("Invalid need %s", need)
+			}
+
+			filter.Needs = append(filter.Needs, need)
+		}
+	}
+
+	return filter, nil
+}
+
+/// swagger:route GET /reservations reservations getReservations
+/// Returns reservations, filtered by query parameters:
+/// upcoming=true, from, to, state, table_id, occasion and needs (accessibility, children, high_chairs, notes).
 /// Responses:
 ///   200: []Reservation
-///   500: []Reservation
+///   400: GenericError
+///   401: GenericError
+///   500: GenericError
 func (server *Server) getReservations(c *gin.Context) {
-	if reservations, err := db.Reservation.GetAll(db.Reservation{}, server.DB); err == nil {
+	filter, err := reservationFilterFromQuery(c)
+
+	if err != nil {
+		respondError(c, errInvalidQuery(err))
+		return
+	}
+
+	if reservations, err := db.Reservation.GetFiltered(db.Reservation{}, server.DB, filter); err == nil {
 		c.JSON(http.StatusOK, reservations)
 	} else {
-		c.JSON(http.StatusInternalServerError, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
@@ -125,7 +509,7 @@ func (server *Server) updateReservationState(c *gin.Context, state db.State) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid reservation ID, must be integer"})
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
 		return
 	}
 
@@ -133,23 +517,54 @@ func (server *Server) updateReservationState(c *gin.Context, state db.State) {
 
 	if err != nil {
 		errorMsg := fmt.Sprintf("Reservation with ID %d does not exist", id)
-		c.JSON(http.StatusBadRequest, GenericError{Error: errorMsg})
+		respondError(c, notFoundError(err, errorMsg))
 		return
 	}
 
-	reservation.State = state
-	err = reservation.Update(server.DB)
+	if err := server.ChangeState(reservation, state); err != nil {
+		if refundErr, ok := err.(*refundError); ok {
+			c.Error(refundErr.err)
+			respondError(c, newError(http.StatusInternalServerError, CodeInternal,
+				"Reservation was cancelled, but deposit could not be refunded"))
+			return
+		}
 
-	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	if err == nil {
-		c.JSON(http.StatusOK, reservation)
-	} else {
-		c.JSON(http.StatusBadRequest, GenericError{Error: err.Error()})
+	c.JSON(http.StatusOK, reservation)
+}
+
+// ChangeState moves reservation to the state and publishes the change to subscribers and webhooks.
+// Paid deposit is refunded, when reservation is cancelled, and kept, when guests have not arrived.
+// Scheduler changes states in the same way. Transitions, which are not allowed, return error.
+func (server *Server) ChangeState(reservation *db.Reservation, state db.State) error {
+	if err := reservation.CanChangeState(state); err != nil {
+		return err
+	}
+
+	reservation.State = state
+
+	if err := reservation.Update(server.DB); err != nil {
+		return err
+	}
+
+	var err error
+
+	switch state {
+	case db.StateCancelled:
+		if refundErr := server.refundDeposit(reservation); refundErr != nil {
+			err = &refundError{err: refundErr}
+		}
+	case db.StateNoShow:
+		err = db.Deposit.Forfeit(db.Deposit{}, server.DB, reservation.ID)
 	}
+
+	// State is already changed, so subscribers are notified even when deposit has failed.
+	server.publish("reservation."+string(state), reservation)
+
+	return err
 }
 
 /// swagger:route POST /reservations/cancel/{id} reservations approveReservation
@@ -157,6 +572,7 @@ func (server *Server) updateReservationState(c *gin.Context, state db.State) {
 /// Responses:
 ///   200: State
 ///   400: GenericError
+///   409: GenericError
 func (server *Server) approveReservation(c *gin.Context) {
 	server.updateReservationState(c, db.StateApproved)
 }
@@ -166,6 +582,92 @@ func (server *Server) approveReservation(c *gin.Context) {
 /// Responses:
 ///   200: State
 ///   400: GenericError
+///   409: GenericError
 func (server *Server) cancelReservation(c *gin.Context) {
 	server.updateReservationState(c, db.StateCancelled)
 }
+
+/// swagger:route POST /reservations/seat/{id} reservations seatReservation
+/// Mark guests of reservation as arrived.
+/// Responses:
+///   200: State
+///   400: GenericError
+///   409: GenericError
+func (server *Server) seatReservation(c *gin.Context) {
+	server.updateReservationState(c, db.StateSeated)
+}
+
+/// swagger:route PUT /reservations/{id} reservations putReservation
+/// Updates single reservation, e.g. one occurrence of recurring series.
+/// Only table, guests, time, duration and special requests could be changed.
+/// Responses:
+///   200: Reservation
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+func (server *Server) putReservation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid reservation ID, must be integer"))
+		return
+	}
+
+	reservation, err := db.Reservation.Find(db.Reservation{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Reservation with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if reservation.State != db.StateCreated && reservation.State != db.StateApproved {
+		errorMsg := fmt.Sprintf("Reservation in state %s could not be changed", reservation.State)
+		respondError(c, errConflict(errorMsg))
+		return
+	}
+
+	// Fields, missing in request body, keep their current values.
+	changes := *reservation
+
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	reservation.TableID = changes.TableID
+	reservation.Guests = changes.Guests
+	reservation.Time = changes.Time
+	reservation.Duration = changes.Duration
+	reservation.Occasion = changes.Occasion
+	reservation.AccessibilityNeeds = changes.AccessibilityNeeds
+	reservation.Children = changes.Children
+	reservation.HighChairs = changes.HighChairs
+	reservation.Notes = changes.Notes
+
+	// Changed occurrence is not replaced, when its series is updated.
+	if reservation.SeriesID != nil {
+		reservation.Detached = true
+	}
+
+	fieldErrors, err := reservation.ValidateUpdate(server.DB)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if fieldErrors = append(fieldErrors, server.tableErrors(reservation)...); len(fieldErrors) != 0 {
+		respondError(c, errValidation(fieldErrors))
+		return
+	}
+
+	if err := reservation.Update(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	server.publish(events.ReservationUpdated, reservation)
+
+	c.JSON(http.StatusOK, reservation)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+// Sends reservation to handler. Every DB query, which is not expected, fails the request.
+func serveReservation(t *testing.T, handler func(*Server) gin.HandlerFunc, body string, expect func(sqlmock.Sqlmock),
+	params ...gin.Param) *httptest.ResponseRecorder {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	expect(mock)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/reservations", bytes.NewBufferString(body))
+	c.Params = params
+
+	handler(&Server{DB: DB})(c)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	return recorder
+}
+
+// Expects queries of field, time and table checks, which are shared by validation and creation.
+// Private events are checked only, when time is not taken by other reservation.
+func expectReservationChecks(taken time.Time, free bool) func(sqlmock.Sqlmock) {
+	return func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE \\(created_at").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE table_id = \\?").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "table_id", "state", "time", "duration"}).
+				AddRow(7, 2, db.StateApproved, taken, 2*time.Hour))
+		if free {
+			mock.ExpectQuery("^SELECT (.+) FROM private_events").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}
+		mock.ExpectQuery("^SELECT (.+) FROM tables WHERE id = \\?").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "places"}).AddRow(2, 4))
+	}
+}
+
+func TestValidateReservationReportsConflictsOfCreation(t *testing.T) {
+	at := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	body := fmt.Sprintf(`{"table_id": 2, "guests": 6, "email": "guest@example.com", "phone": "+97336123456",
+		"full_name": "Guest", "time": "%s", "duration": %d}`, at.Format(time.RFC3339), int64(2*time.Hour))
+
+	validation := serveReservation(t, func(server *Server) gin.HandlerFunc { return server.validateReservation }, body,
+		func(mock sqlmock.Sqlmock) {
+			expectReservationChecks(at.Add(time.Hour), false)(mock)
+			// Guest profile and restrictions are only looked up, nothing is inserted.
+			mock.ExpectQuery("^SELECT (.+) FROM guests WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery("^SELECT (.+) FROM blocklist").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		})
+
+	creation := serveReservation(t, func(server *Server) gin.HandlerFunc { return server.postReservation }, body,
+		expectReservationChecks(at.Add(time.Hour), false))
+
+	result := ReservationValidation{}
+	assert.Equal(t, http.StatusOK, validation.Code)
+	assert.NoError(t, json.Unmarshal(validation.Body.Bytes(), &result))
+
+	genericError := GenericError{}
+	assert.Equal(t, http.StatusBadRequest, creation.Code)
+	assert.NoError(t, json.Unmarshal(creation.Body.Bytes(), &genericError))
+
+	expected := []db.FieldError{
+		{Field: "time", Error: "This time was already taken"},
+		{Field: "guests", Error: "Invalid amount of guests, maximum amount for this table is 4"},
+	}
+
+	assert.False(t, result.Valid)
+	assert.Equal(t, expected, result.Errors)
+	assert.Equal(t, expected, genericError.Details)
+}
+
+func TestValidateReservationDoesNotInsert(t *testing.T) {
+	at := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	body := fmt.Sprintf(`{"table_id": 2, "guests": 2, "email": "guest@example.com", "phone": "+97336123456",
+		"full_name": "Guest", "time": "%s", "duration": %d}`, at.Format(time.RFC3339), int64(2*time.Hour))
+
+	validation := serveReservation(t, func(server *Server) gin.HandlerFunc { return server.validateReservation }, body,
+		func(mock sqlmock.Sqlmock) {
+			// Reservation of the table ends before requested time.
+			expectReservationChecks(at.Add(-2*time.Hour), true)(mock)
+			mock.ExpectQuery("^SELECT (.+) FROM guests WHERE").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery("^SELECT (.+) FROM blocklist").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		})
+
+	result := ReservationValidation{}
+	assert.Equal(t, http.StatusOK, validation.Code)
+	assert.NoError(t, json.Unmarshal(validation.Body.Bytes(), &result))
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Errors)
+}
+
+func TestChangeStateRejectsInvalidTransition(t *testing.T) {
+	tests := []struct {
+		state   db.State
+		handler func(server *Server) gin.HandlerFunc
+	}{
+		{db.StateCancelled, func(server *Server) gin.HandlerFunc { return server.approveReservation }},
+		{db.StateNoShow, func(server *Server) gin.HandlerFunc { return server.seatReservation }},
+		{db.StateCompleted, func(server *Server) gin.HandlerFunc { return server.cancelReservation }},
+		// Reservation is not verified.
+		{db.StateCreated, func(server *Server) gin.HandlerFunc { return server.approveReservation }},
+	}
+
+	for _, test := range tests {
+		recorder := serveReservation(t, test.handler, "", func(mock sqlmock.Sqlmock) {
+			// Only reservation is loaded, its state is not updated.
+			mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "table_id", "state"}).AddRow(7, 2, test.state))
+		}, gin.Param{Key: "id", Value: "7"})
+
+		assert.Equal(t, http.StatusConflict, recorder.Code, string(test.state))
+	}
+}
+
+// Keeps sent messages instead of delivering them.
+type sentMessages []notify.Message
+
+func (messages *sentMessages) Notify(message notify.Message) error {
+	*messages = append(*messages, message)
+	return nil
+}
+
+func TestResendVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		recent int
+		status int
+		sent   int
+	}{
+		{"sent", 0, http.StatusNoContent, 1},
+		{"too soon", 1, http.StatusTooManyRequests, 0},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "state", "email", "phone"}).
+				AddRow(7, db.StateCreated, "guest@example.com", "+97336123456"))
+		mock.ExpectQuery("^SELECT COUNT(.+) FROM verifications").
+			WithArgs(int64(db.VerificationResendInterval.Seconds()), 7).
+			WillReturnRows(sqlmock.NewRows([]string{"total", "recent"}).AddRow(1, test.recent))
+
+		if test.sent != 0 {
+			// Code is sent to the same channel as before.
+			mock.ExpectQuery("^SELECT (.+) FROM verifications WHERE reservation_id = \\?").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "reservation_id", "channel"}).AddRow(3, 7, "sms"))
+			mock.ExpectExec("^INSERT INTO verifications").WillReturnResult(sqlmock.NewResult(4, 1))
+		}
+
+		messages := sentMessages{}
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodPost, "/reservations/verify/7/resend", nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+		server := Server{DB: DB, Notifier: &messages}
+		server.resendVerification(c)
+
+		assert.Equal(t, test.status, recorder.Code, test.name)
+		assert.Len(t, messages, test.sent, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+
+		if test.sent != 0 {
+			assert.Equal(t, notify.ChannelSMS, messages[0].Channel)
+			assert.Equal(t, "+97336123456", messages[0].To)
+		}
+
+		DB.Close()
+	}
+}
+
+func TestGetReservationRequiresToken(t *testing.T) {
+	server := Server{TokenSecret: []byte("secret")}
+
+	tests := []struct {
+		token  string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{server.reservationToken(8), http.StatusUnauthorized},
+		{server.reservationToken(7), http.StatusOK},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		server.DB = sqlx.NewDb(mockDB, "sqlmock")
+
+		if test.status == http.StatusOK {
+			mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "guest@example.com"))
+			mock.ExpectQuery("^SELECT (.+) FROM deposits").WillReturnError(sql.ErrNoRows)
+		}
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodGet, "/reservations/7?token="+test.token, nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+
+		server.getReservation(c)
+
+		assert.Equal(t, test.status, recorder.Code, test.token)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		server.DB.Close()
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+)
+
+// Validates series and links it to table and guest profile.
+func (server *Server) prepareSeries(series *db.ReservationSeries) error {
+	if err := series.Validate(); err != nil {
+		return errInvalid(err)
+	}
+
+	// Validate, that table with TableID exists.
+	table, err := db.Table.Find(db.Table{}, server.DB, series.TableID)
+	if err != nil {
+		return errValidation([]db.FieldError{{Field: "table_id", Error: fmt.Sprintf("Invalid table id %d", series.TableID)}})
+	}
+
+	// Validate, that number of guests not bigger that table has.
+	if series.Guests > table.Places {
+		return errValidation([]db.FieldError{{
+			Field: "guests",
+			Error: fmt.Sprintf("Invalid amount of guests, maximum amount for this table is %d", table.Places),
+		}})
+	}
+
+	contact := db.Reservation{Email: series.Email, Phone: series.Phone, FullName: series.FullName}
+
+	if err := contact.LinkGuest(server.DB); err != nil {
+		return err
+	}
+
+	series.GuestID = contact.GuestID
+
+	return nil
+}
+
+// Books upcoming occurrences of series and publishes created reservations.
+func (server *Server) bookSeries(c *gin.Context, series *db.ReservationSeries, status int) {
+	result, err := series.Book(server.DB, time.Now())
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	for _, reservation := range result.Reservations {
+		server.publish(events.ReservationCreated, reservation)
+	}
+
+	c.JSON(status, result)
+}
+
+// Cancels upcoming reservations of series the same way as single reservations,
+// so deposits are refunded and cancellation is published.
+func (server *Server) cancelUpcoming(series *db.ReservationSeries) error {
+	reservations, err := series.GetUpcoming(server.DB)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range *reservations {
+		reservation := &(*reservations)[i]
+
+		if err := server.ChangeState(reservation, db.StateCancelled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/// swagger:route GET /series series listSeries
+/// List all recurring reservation series.
+/// Responses:
+///   200: []ReservationSeries
+///   500: GenericError
+func (server *Server) listSeries(c *gin.Context) {
+	series, err := db.ReservationSeries.GetAll(db.ReservationSeries{}, server.DB)
+
+	if err == nil {
+		c.JSON(http.StatusOK, series)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route GET /series/{id} series getSeries
+/// Returns series with all its reservations.
+/// Responses:
+///   200: SeriesResult
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid series ID, must be int"))
+		return
+	}
+
+	series, err := db.ReservationSeries.Find(db.ReservationSeries{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Series with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	reservations, err := series.GetOccurrences(server.DB)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, db.SeriesResult{
+		Series:       *series,
+		Reservations: *reservations,
+		Conflicts:    make([]db.SeriesConflict, 0),
+	})
+}
+
+/// swagger:route POST /series series postSeries
+/// Creates recurring series and books its occurrences.
+/// Occurrences, overlapping with other reservations, are returned as conflicts.
+/// Responses:
+///   201: SeriesResult
+///   400: GenericError
+func (server *Server) postSeries(c *gin.Context) {
+	series := db.ReservationSeries{}
+
+	if err := c.ShouldBindJSON(&series); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := server.prepareSeries(&series); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := series.Insert(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	server.bookSeries(c, &series, http.StatusCreated)
+}
+
+/// swagger:route PUT /series/{id} series putSeries
+/// Updates whole series and its upcoming occurrences in one transaction:
+/// occurrences at the same time are updated, other ones are cancelled, new ones are booked.
+/// Individually changed occurrences are kept.
+/// Responses:
+///   200: SeriesResult
+///   400: GenericError
+///   404: GenericError
+///   409: GenericError
+func (server *Server) putSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid series ID, must be int"))
+		return
+	}
+
+	existing, err := db.ReservationSeries.Find(db.ReservationSeries{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Series with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	if existing.State == db.SeriesCancelled {
+		respondError(c, errConflict("Cancelled series could not be updated"))
+		return
+	}
+
+	series := db.ReservationSeries{}
+
+	if err := c.ShouldBindJSON(&series); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := server.prepareSeries(&series); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	series.ID = id
+	series.State = db.SeriesActive
+
+	changes, err := series.Rebook(server.DB, time.Now())
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Changes are published only after they are saved.
+	for i := range changes.Cancelled {
+		reservation := &changes.Cancelled[i]
+
+		if err := server.refundDeposit(reservation); err != nil {
+			c.Error(err)
+		}
+
+		server.publish(events.ReservationCancelled, reservation)
+	}
+
+	for _, reservation := range changes.Updated {
+		server.publish(events.ReservationUpdated, reservation)
+	}
+
+	for _, reservation := range changes.Created {
+		server.publish(events.ReservationCreated, reservation)
+	}
+
+	c.JSON(http.StatusOK, changes.Result)
+}
+
+/// swagger:route DELETE /series/{id} series deleteSeries
+/// Cancels series and all its upcoming reservations.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid series ID, must be int"))
+		return
+	}
+
+	series, err := db.ReservationSeries.Find(db.ReservationSeries{}, server.DB, id)
+
+	if err != nil {
+		errorMsg := fmt.Sprintf("Series with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	series.State = db.SeriesCancelled
+
+	if err := series.Update(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := server.cancelUpcoming(series); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -4,26 +4,68 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
 )
 
 /// swagger:route GET /tables tables listTables
 /// List all tables.
+/// With from and to query parameters (RFC 3339) tables, blocked by private events in this range, are omitted.
 /// Responses:
 ///   200: []Table
+///   400: GenericError
 ///   500: GenericError
 func (server *Server) listTables(c *gin.Context) {
-	table, err := db.Table.GetAll(db.Table{}, server.DB)
+	tables, err := db.Table.GetAll(db.Table{}, server.DB)
 
-	if err == nil {
-		c.JSON(http.StatusOK, table)
-	} else {
-		c.JSON(http.StatusInternalServerError, GenericError{Error: err.Error()})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if c.Query("from") == "" && c.Query("to") == "" {
+		c.JSON(http.StatusOK, tables)
+		return
+	}
+
+	from, errFrom := time.Parse(time.RFC3339, c.Query("from"))
+	to, errTo := time.Parse(time.RFC3339, c.Query("to"))
+
+	if errFrom != nil || errTo != nil || !to.After(from) {
+		respondError(c, newError(http.StatusBadRequest, CodeInvalidParameter, "Invalid time range, from and to should be in RFC 3339 format"))
+		return
+	}
+
+	privateEvents, err := db.PrivateEvent.GetOverlapping(db.PrivateEvent{}, server.DB, from, to)
+
+	if err != nil {
+		respondError(c, err)
+		return
 	}
+
+	available := make([]db.Table, 0)
+
+	for _, table := range *tables {
+		blocked := false
+
+		for _, event := range *privateEvents {
+			if event.BlocksTable(table.ID) {
+				blocked = true
+				break
+			}
+		}
+
+		if !blocked {
+			available = append(available, table)
+		}
+	}
+
+	c.JSON(http.StatusOK, available)
 }
 
 /// swagger:route GET /tables/{id} tables getTable
@@ -36,7 +78,7 @@ func (server *Server) getTable(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid table ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid table ID, must be int"))
 		return
 	}
 
@@ -46,7 +88,7 @@ func (server *Server) getTable(c *gin.Context) {
 		c.JSON(http.StatusOK, table)
 	} else {
 		errorMsg := fmt.Sprintf("Table with id %d could not be found", id)
-		c.JSON(http.StatusNotFound, GenericError{Error: errorMsg})
+		respondError(c, notFoundError(err, errorMsg))
 	}
 }
 
@@ -59,21 +101,22 @@ func (server *Server) postTable(c *gin.Context) {
 	table := db.Table{}
 
 	if err := c.ShouldBindJSON(&table); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+		respondError(c, errInvalidPayload(err))
 		return
 	}
 
-	if table.Places <= 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Places count should be more than 0"})
+	if err := table.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
 	err := table.Insert(server.DB)
 
 	if err == nil {
+		server.publish(events.TableCreated, table)
 		c.JSON(http.StatusCreated, table)
 	} else {
-		c.JSON(http.StatusBadRequest, GenericError{Error: err.Error()})
+		respondError(c, err)
 	}
 }
 
@@ -87,19 +130,19 @@ func (server *Server) putTable(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid table ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid table ID, must be int"))
 		return
 	}
 
 	table := db.Table{}
 
 	if err := c.ShouldBindJSON(&table); err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid request payload"})
+		respondError(c, errInvalidPayload(err))
 		return
 	}
 
-	if table.Places <= 0 {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Places count should be more than 0"})
+	if err := table.Validate(); err != nil {
+		respondError(c, errInvalid(err))
 		return
 	}
 
@@ -108,8 +151,9 @@ func (server *Server) putTable(c *gin.Context) {
 	// Check if ID exists.
 	err = table.Update(server.DB)
 	if err != nil {
-		c.JSON(http.StatusNotFound, err.Error())
+		respondError(c, notFoundError(err, fmt.Sprintf("Table with id %d could not be found", id)))
 	} else {
+		server.publish(events.TableUpdated, table)
 		c.JSON(http.StatusOK, table)
 	}
 }
@@ -123,7 +167,7 @@ func (server *Server) deleteTable(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, GenericError{Error: "Invalid table ID, must be int"})
+		respondError(c, errInvalidParameter("id", "Invalid table ID, must be int"))
 		return
 	}
 
@@ -131,8 +175,9 @@ func (server *Server) deleteTable(c *gin.Context) {
 
 	// Check if ID exists.
 	if err == nil {
+		server.publish(events.TableDeleted, gin.H{"id": id})
 		c.JSON(http.StatusNoContent, nil)
 	} else {
-		c.JSON(http.StatusNotFound, err.Error())
+		respondError(c, notFoundError(err, fmt.Sprintf("Table with id %d could not be found", id)))
 	}
 }
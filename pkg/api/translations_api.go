@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/locale"
+)
+
+// Negotiates language of menu content with "lang" query parameter or Accept-Language header.
+func (server *Server) language(c *gin.Context) string {
+	lang := server.Locales.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"))
+
+	c.Header("Content-Language", lang)
+	c.Header("Vary", "Accept-Language")
+
+	return lang
+}
+
+// Translates menu items to negotiated language.
+func (server *Server) translateMenu(c *gin.Context, menuItems []db.MenuItem) error {
+	if lang := server.language(c); lang != server.Locales.Default {
+		return db.TranslateMenu(server.DB, menuItems, lang)
+	}
+
+	return nil
+}
+
+// Translates menu categories to negotiated language.
+func (server *Server) translateCategories(c *gin.Context, categories []db.MenuCategory) error {
+	if lang := server.language(c); lang != server.Locales.Default {
+		return db.TranslateCategories(server.DB, categories, lang)
+	}
+
+	return nil
+}
+
+// Parses translation from request body and validates its language from "lang" path parameter.
+func (server *Server) bindTranslation(c *gin.Context) (*db.Translation, error) {
+	translation := db.Translation{}
+
+	if err := c.ShouldBindJSON(&translation); err != nil {
+		return nil, errInvalidPayload(err)
+	}
+
+	translation.Lang = locale.Normalize(c.Param("lang"))
+
+	if translation.Lang == server.Locales.Default {
+		errorMsg := fmt.Sprintf("Content in default language %s should be changed in the resource itself", translation.Lang)
+		return nil, errInvalidParameter("lang", errorMsg)
+	}
+
+	if !server.Locales.IsSupported(translation.Lang) {
+		errorMsg := fmt.Sprintf("Unsupported language, should be one of %s", strings.Join(server.Locales.Supported, ", "))
+		return nil, errInvalidParameter("lang", errorMsg)
+	}
+
+	if err := translation.Validate(); err != nil {
+		return nil, errInvalid(err)
+	}
+
+	return &translation, nil
+}
+
+/// swagger:route GET /menu/{id}/translations menu listMenuItemTranslations
+/// List translations of menu item.
+/// Responses:
+///   200: []Translation
+///   400: GenericError
+///   404: GenericError
+func (server *Server) listMenuItemTranslations(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	if _, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
+	translations, err := db.MenuItem.GetTranslations(db.MenuItem{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, translations)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /menu/{id}/translations/{lang} menu putMenuItemTranslation
+/// Creates or replaces translation of menu item.
+/// Responses:
+///   200: Translation
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putMenuItemTranslation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	translation, err := server.bindTranslation(c)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	menuItem, err := db.MenuItem.Find(db.MenuItem{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Menu item with id %d could not be found", id)))
+		return
+	}
+
+	if err := menuItem.SaveTranslation(server.DB, *translation); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+/// swagger:route DELETE /menu/{id}/translations/{lang} menu deleteMenuItemTranslation
+/// Deletes translation of menu item.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteMenuItemTranslation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid menu item ID, must be int"))
+		return
+	}
+
+	lang := locale.Normalize(c.Param("lang"))
+
+	if err := db.MenuItem.DestroyTranslation(db.MenuItem{}, server.DB, id, lang); err != nil {
+		errorMsg := fmt.Sprintf("Translation of menu item with id %d to %s could not be found", id, lang)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+/// swagger:route GET /categories/{category_id}/translations menu listCategoryTranslations
+/// List translations of menu category.
+/// Responses:
+///   200: []Translation
+///   400: GenericError
+///   404: GenericError
+func (server *Server) listCategoryTranslations(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("category_id", "Invalid category ID, must be int"))
+		return
+	}
+
+	if _, err := db.MenuCategory.Find(db.MenuCategory{}, server.DB, id); err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Category with id %d could not be found", id)))
+		return
+	}
+
+	translations, err := db.MenuCategory.GetTranslations(db.MenuCategory{}, server.DB, id)
+
+	if err == nil {
+		c.JSON(http.StatusOK, translations)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /categories/{id}/translations/{lang} menu putCategoryTranslation
+/// Creates or replaces translation of menu category.
+/// Responses:
+///   200: Translation
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putCategoryTranslation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid category ID, must be int"))
+		return
+	}
+
+	translation, err := server.bindTranslation(c)
+
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	category, err := db.MenuCategory.Find(db.MenuCategory{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Category with id %d could not be found", id)))
+		return
+	}
+
+	if err := category.SaveTranslation(server.DB, *translation); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Categories have no description.
+	translation.Description = ""
+
+	c.JSON(http.StatusOK, translation)
+}
+
+/// swagger:route DELETE /categories/{id}/translations/{lang} menu deleteCategoryTranslation
+/// Deletes translation of menu category.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteCategoryTranslation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid category ID, must be int"))
+		return
+	}
+
+	lang := locale.Normalize(c.Param("lang"))
+
+	if err := db.MenuCategory.DestroyTranslation(db.MenuCategory{}, server.DB, id, lang); err != nil {
+		errorMsg := fmt.Sprintf("Translation of category with id %d to %s could not be found", id, lang)
+		respondError(c, notFoundError(err, errorMsg))
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
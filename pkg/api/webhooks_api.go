@@ -0,0 +1,210 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+)
+
+// Number of deliveries, returned by deliveries log endpoint.
+const deliveriesLimit = 100
+
+// Secret is write-only, so it is removed before webhook is returned.
+func redactWebhook(hook *db.Webhook) {
+	hook.Secret = ""
+}
+
+func validateWebhook(hook *db.Webhook) error {
+	target, err := url.Parse(hook.URL)
+
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("URL is invalid, should be absolute http or https URL")
+	}
+
+	if len(hook.Secret) == 0 {
+		return errors.New("Secret should not be empty")
+	}
+
+	for _, event := range hook.Events {
+		if !events.IsKnown(event) {
+			return fmt.Errorf("Unknown event %s", event)
+		}
+	}
+
+	return nil
+}
+
+/// swagger:route GET /webhooks webhooks listWebhooks
+/// List all webhooks.
+/// Responses:
+///   200: []Webhook
+///   500: GenericError
+func (server *Server) listWebhooks(c *gin.Context) {
+	webhooks, err := db.Webhook.GetAll(db.Webhook{}, server.DB)
+
+	if err == nil {
+		for i := range *webhooks {
+			redactWebhook(&(*webhooks)[i])
+		}
+
+		c.JSON(http.StatusOK, webhooks)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route GET /webhooks/{id} webhooks getWebhook
+/// Returns webhook.
+/// Responses:
+///   200: Webhook
+///   400: GenericError
+///   404: GenericError
+func (server *Server) getWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid webhook ID, must be int"))
+		return
+	}
+
+	hook, err := db.Webhook.Find(db.Webhook{}, server.DB, id)
+
+	if err == nil {
+		redactWebhook(hook)
+		c.JSON(http.StatusOK, hook)
+	} else {
+		errorMsg := fmt.Sprintf("Webhook with id %d could not be found", id)
+		respondError(c, notFoundError(err, errorMsg))
+	}
+}
+
+/// swagger:route POST /webhooks webhooks postWebhook
+/// Creates webhook.
+/// Responses:
+///   201: Webhook
+///   400: GenericError
+func (server *Server) postWebhook(c *gin.Context) {
+	hook := db.Webhook{}
+
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	if err := validateWebhook(&hook); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	err := hook.Insert(server.DB)
+
+	if err == nil {
+		redactWebhook(&hook)
+		c.JSON(http.StatusCreated, hook)
+	} else {
+		respondError(c, err)
+	}
+}
+
+/// swagger:route PUT /webhooks/{id} webhooks putWebhook
+/// Updates webhook.
+/// Responses:
+///   200: Webhook
+///   400: GenericError
+///   404: GenericError
+func (server *Server) putWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid webhook ID, must be int"))
+		return
+	}
+
+	existing, err := db.Webhook.Find(db.Webhook{}, server.DB, id)
+
+	if err != nil {
+		respondError(c, notFoundError(err, fmt.Sprintf("Webhook with id %d could not be found", id)))
+		return
+	}
+
+	hook := db.Webhook{}
+
+	if err := c.ShouldBindJSON(&hook); err != nil {
+		respondError(c, errInvalidPayload(err))
+		return
+	}
+
+	// Secret is not returned to clients, so current one is kept, when it is not sent.
+	if hook.Secret == "" {
+		hook.Secret = existing.Secret
+	}
+
+	if err := validateWebhook(&hook); err != nil {
+		respondError(c, errInvalid(err))
+		return
+	}
+
+	hook.ID = id
+
+	if err := hook.Update(server.DB); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	redactWebhook(&hook)
+	c.JSON(http.StatusOK, hook)
+}
+
+/// swagger:route DELETE /webhooks/{id} webhooks deleteWebhook
+/// Deletes webhook.
+/// Responses:
+///   204:
+///   404: GenericError
+func (server *Server) deleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid webhook ID, must be int"))
+		return
+	}
+
+	err = db.Webhook.Destroy(db.Webhook{}, server.DB, id)
+
+	// Check if ID exists.
+	if err == nil {
+		c.JSON(http.StatusNoContent, nil)
+	} else {
+		respondError(c, notFoundError(err, fmt.Sprintf("Webhook with id %d could not be found", id)))
+	}
+}
+
+/// swagger:route GET /webhooks/{id}/deliveries webhooks listWebhookDeliveries
+/// List latest delivery attempts of webhook.
+/// Responses:
+///   200: []WebhookDelivery
+///   400: GenericError
+///   500: GenericError
+func (server *Server) listWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	if err != nil {
+		respondError(c, errInvalidParameter("id", "Invalid webhook ID, must be int"))
+		return
+	}
+
+	deliveries, err := db.WebhookDelivery.GetByWebhook(db.WebhookDelivery{}, server.DB, id, deliveriesLimit)
+
+	if err == nil {
+		c.JSON(http.StatusOK, deliveries)
+	} else {
+		respondError(c, err)
+	}
+}
@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Status of calendar event.
+type Status string
+
+const (
+	// StatusTentative is status of not yet confirmed event.
+	StatusTentative Status = "TENTATIVE"
+	// StatusConfirmed is status of confirmed event.
+	StatusConfirmed Status = "CONFIRMED"
+	// StatusCancelled is status of cancelled event.
+	StatusCancelled Status = "CANCELLED"
+)
+
+// ContentType is MIME type of iCalendar files.
+const ContentType = "text/calendar; charset=utf-8"
+
+const timeFormat = "20060102T150405Z"
+
+// Maximum length of content line in octets, longer lines are folded.
+const lineLength = 75
+
+// Event is single calendar event (VEVENT).
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      Status
+	Start       time.Time
+	End         time.Time
+}
+
+// Calendar is collection of events (VCALENDAR).
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// Escapes text value according to RFC 5545.
+func escape(value string) string {
+	return escaper.Replace(value)
+}
+
+// Splits line into parts, which fit line length limit, without breaking UTF-8 characters.
+func fold(line string) string {
+	var builder strings.Builder
+
+	limit := lineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with space.
+		limit = lineLength - 1
+	}
+
+	builder.WriteString(line)
+
+	return builder.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// Write writes calendar in iCalendar format.
+func (calendar Calendar) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(timeFormat)
+
+	line := func(format string, args ...interface{}) {
+		writer.WriteString(fold(fmt.Sprintf(format, args...)))
+		writer.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Palestine Nights//Reservations//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+
+	if calendar.Name != "" {
+		line("X-WR-CALNAME:%s", escape(calendar.Name))
+	}
+
+	for _, event := range calendar.Events {
+		line("BEGIN:VEVENT")
+		line("UID:%s", event.UID)
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART:%s", event.Start.UTC().Format(timeFormat))
+		line("DTEND:%s", event.End.UTC().Format(timeFormat))
+		line("SUMMARY:%s", escape(event.Summary))
+
+		if event.Description != "" {
+			line("DESCRIPTION:%s", escape(event.Description))
+		}
+
+		if event.Location != "" {
+			line("LOCATION:%s", escape(event.Location))
+		}
+
+		if event.Status != "" {
+			line("STATUS:%s", event.Status)
+		}
+
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return writer.Flush()
+}
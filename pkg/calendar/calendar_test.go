@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `Table 1\, near window\; 4 seats\nNo smoking \\ inside`,
+		escape("Table 1, near window; 4 seats\nNo smoking \\ inside"))
+}
+
+func TestFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("a", 100)
+	folded := fold(line)
+
+	parts := strings.Split(folded, "\r\n")
+
+	assert.Len(t, parts, 2)
+	assert.Len(t, parts[0], lineLength)
+	assert.True(t, strings.HasPrefix(parts[1], " "))
+	assert.Equal(t, line, parts[0]+strings.TrimPrefix(parts[1], " "))
+}
+
+func TestFoldMultibyte(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("ح", 50)
+
+	for _, part := range strings.Split(fold(line), "\r\n") {
+		assert.True(t, len(part) <= lineLength)
+		assert.True(t, isRuneStart(strings.TrimPrefix(part, " ")[0]))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2019, 11, 25, 20, 0, 0, 0, time.UTC)
+	calendar := Calendar{
+		Name: "Reservations",
+		Events: []Event{
+			{
+				UID:     "reservation-1@palestine-nights",
+				Summary: "Reservation for 2 guests",
+				Status:  StatusConfirmed,
+				Start:   start,
+				End:     start.Add(2 * time.Hour),
+			},
+		},
+	}
+
+	buffer := bytes.Buffer{}
+	assert.Nil(t, calendar.Write(&buffer))
+
+	output := buffer.String()
+
+	assert.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, output, "DTSTART:20191125T200000Z\r\n")
+	assert.Contains(t, output, "DTEND:20191125T220000Z\r\n")
+	assert.Contains(t, output, "STATUS:CONFIRMED\r\n")
+	assert.True(t, strings.HasSuffix(output, "END:VCALENDAR\r\n"))
+}
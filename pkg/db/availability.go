@@ -0,0 +1,212 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+var clockRegexp = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// Validates and normalizes availability windows, errors have "availability" field.
+func validateAvailability(errs *ValidationErrors, windows []AvailabilityWindow) {
+	for i := range windows {
+		window := &windows[i]
+		field := fmt.Sprintf("availability[%d]", i)
+
+		for j, weekday := range window.Weekdays {
+			window.Weekdays[j] = strings.ToLower(strings.TrimSpace(weekday))
+
+			errs.check(StringList(weekdayNames).Contains(window.Weekdays[j]), field+".weekdays",
+				"Weekdays are invalid, should be one of "+strings.Join(weekdayNames, ", "))
+		}
+
+		window.StartTime = strings.TrimSpace(window.StartTime)
+		errs.check(window.StartTime == "" || clockRegexp.MatchString(window.StartTime), field+".start_time",
+			"Start time should be in HH:MM format")
+
+		window.EndTime = strings.TrimSpace(window.EndTime)
+		errs.check(window.EndTime == "" || clockRegexp.MatchString(window.EndTime), field+".end_time",
+			"End time should be in HH:MM format")
+
+		errs.check(window.StartTime == "" || window.StartTime != window.EndTime, field+".end_time",
+			"End time should differ from start time")
+
+		errs.check(window.StartsOn == nil || window.EndsOn == nil || !window.EndsOn.Before(*window.StartsOn),
+			field+".ends_on", "End date should not be before start date")
+	}
+}
+
+// Matches returns true, when window includes specified time.
+// Time of day and weekday are taken in restaurant location.
+func (window AvailabilityWindow) Matches(at time.Time) bool {
+	at = at.In(Location)
+
+	clock := at.Format("15:04")
+	start, end := window.StartTime, window.EndTime
+
+	if end == "" {
+		end = "24:00"
+	}
+
+	// Day, when time range starts, is previous one after midnight of overnight range.
+	day := at
+
+	if end > start {
+		if clock < start || clock >= end {
+			return false
+		}
+	} else if clock < end {
+		day = at.AddDate(0, 0, -1)
+	} else if clock < start {
+		return false
+	}
+
+	if len(window.Weekdays) != 0 && !window.Weekdays.Contains(weekdayNames[day.Weekday()]) {
+		return false
+	}
+
+	// Compare dates only, window dates are stored without time.
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	if window.StartsOn != nil && date.Before(window.StartsOn.UTC()) {
+		return false
+	}
+
+	if window.EndsOn != nil && date.After(window.EndsOn.UTC()) {
+		return false
+	}
+
+	return true
+}
+
+// Returns true, when there are no windows or any of them includes specified time.
+func availableAt(windows []AvailabilityWindow, at time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	for _, window := range windows {
+		if window.Matches(at) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Loads availability windows, which belong to menu items or categories with specified IDs, grouped by their IDs.
+func loadAvailability(db *sqlx.DB, column string, ids []uint64) (map[uint64][]AvailabilityWindow, error) {
+	byOwner := make(map[uint64][]AvailabilityWindow)
+
+	if len(ids) == 0 {
+		return byOwner, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM availability_windows WHERE `+column+` IN (?) ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]AvailabilityWindow, 0)
+	if err := db.Select(&windows, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		owner := window.MenuItemID
+		if column == "category_id" {
+			owner = window.CategoryID
+		}
+
+		byOwner[*owner] = append(byOwner[*owner], window)
+	}
+
+	return byOwner, nil
+}
+
+// Loads availability windows for every menu item in the list.
+func loadMenuAvailability(db *sqlx.DB, menuItems []MenuItem) error {
+	ids := make([]uint64, len(menuItems))
+	for i, menuItem := range menuItems {
+		ids[i] = menuItem.ID
+	}
+
+	byItem, err := loadAvailability(db, "menu_item_id", ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range menuItems {
+		menuItems[i].Availability = append(make([]AvailabilityWindow, 0), byItem[menuItems[i].ID]...)
+	}
+
+	return nil
+}
+
+// Loads availability windows for every category in the list.
+func loadCategoryAvailability(db *sqlx.DB, categories []MenuCategory) error {
+	ids := make([]uint64, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+
+	byCategory, err := loadAvailability(db, "category_id", ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range categories {
+		categories[i].Availability = append(make([]AvailabilityWindow, 0), byCategory[categories[i].ID]...)
+	}
+
+	return nil
+}
+
+// Replaces availability windows, which belong to menu item or category in specified column.
+func saveAvailability(tx *sqlx.Tx, column string, id uint64, windows []AvailabilityWindow) error {
+	if _, err := tx.Exec(`DELETE FROM availability_windows WHERE `+column+` = ?`, id); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO availability_windows (` + column + `, weekdays, start_time, end_time, starts_on, ends_on)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	for _, window := range windows {
+		_, err := tx.Exec(query, id, window.Weekdays, window.StartTime, window.EndTime, window.StartsOn, window.EndsOn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AvailableMenu returns active menu items, which could be ordered at specified time
+// according to availability of items and their categories.
+func AvailableMenu(db *sqlx.DB, menuItems []MenuItem, at time.Time) ([]MenuItem, error) {
+	categories, err := MenuCategory.GetAll(MenuCategory{}, db)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[uint64][]AvailabilityWindow)
+	for _, category := range *categories {
+		byCategory[category.ID] = category.Availability
+	}
+
+	available := make([]MenuItem, 0)
+
+	for _, menuItem := range menuItems {
+		if menuItem.Active && availableAt(menuItem.Availability, at) && availableAt(byCategory[menuItem.CategoryID], at) {
+			available = append(available, menuItem)
+		}
+	}
+
+	return available, nil
+}
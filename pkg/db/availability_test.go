@@ -0,0 +1,107 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailabilityWindowMatches(t *testing.T) {
+	// 2019-11-29 is Friday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2019, 11, day, hour, minute, 0, 0, Location)
+	}
+	fridayDate := time.Date(2019, 11, 29, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	breakfast := AvailabilityWindow{StartTime: "07:00", EndTime: "11:30"}
+	lateNight := AvailabilityWindow{Weekdays: StringList{"fri"}, StartTime: "22:00", EndTime: "02:00"}
+
+	tests := []struct {
+		name     string
+		window   AvailabilityWindow
+		at       time.Time
+		expected bool
+	}{
+		{"any time", AvailabilityWindow{}, at(29, 3, 0), true},
+		{"during breakfast", breakfast, at(29, 7, 0), true},
+		{"before breakfast", breakfast, at(29, 6, 59), false},
+		{"end of breakfast", breakfast, at(29, 11, 30), false},
+		{"until end of day", AvailabilityWindow{StartTime: "18:00"}, at(29, 23, 59), true},
+		{"matching weekday", AvailabilityWindow{Weekdays: StringList{"fri", "sat"}}, at(29, 12, 0), true},
+		{"other weekday", AvailabilityWindow{Weekdays: StringList{"sat"}}, at(29, 12, 0), false},
+		{"overnight before midnight", lateNight, at(29, 23, 0), true},
+		{"overnight after midnight", lateNight, at(30, 1, 0), true},
+		{"overnight after midnight of other weekday", lateNight, at(29, 1, 0), false},
+		{"overnight after end", lateNight, at(30, 3, 0), false},
+		{"before season", AvailabilityWindow{StartsOn: &december}, at(29, 12, 0), false},
+		{"last day of season", AvailabilityWindow{EndsOn: &fridayDate}, at(29, 20, 0), true},
+		{"after season", AvailabilityWindow{EndsOn: &fridayDate}, at(30, 12, 0), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.window.Matches(test.at))
+		})
+	}
+}
+
+func TestAvailableAt(t *testing.T) {
+	noon := time.Date(2019, 11, 29, 12, 0, 0, 0, Location)
+	breakfast := AvailabilityWindow{StartTime: "07:00", EndTime: "11:30"}
+	lunch := AvailabilityWindow{StartTime: "12:00", EndTime: "15:00"}
+
+	assert.True(t, availableAt(nil, noon))
+	assert.True(t, availableAt([]AvailabilityWindow{breakfast, lunch}, noon))
+	assert.False(t, availableAt([]AvailabilityWindow{breakfast}, noon))
+}
+
+func TestValidateAvailability(t *testing.T) {
+	december := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	november := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	menuItem := MenuItem{Name: "Shakshuka", Price: 2500, Availability: []AvailabilityWindow{
+		{Weekdays: StringList{" FRI "}, StartTime: "07:00", EndTime: "11:00"},
+		{StartTime: "7:00", EndTime: "24:00", StartsOn: &december, EndsOn: &november},
+		{Weekdays: StringList{"friday"}, StartTime: "10:00", EndTime: "10:00"},
+	}}
+
+	err := menuItem.Validate()
+
+	assert.Equal(t, StringList{"fri"}, menuItem.Availability[0].Weekdays)
+	if assert.IsType(t, ValidationErrors{}, err) {
+		fields := make([]string, 0)
+		for _, fieldErr := range err.(ValidationErrors) {
+			fields = append(fields, fieldErr.Field)
+		}
+
+		assert.Equal(t, []string{
+			"availability[1].start_time",
+			"availability[1].end_time",
+			"availability[1].ends_on",
+			"availability[2].weekdays",
+			"availability[2].end_time",
+		}, fields)
+	}
+}
+
+func TestAvailabilityWindowMatchesInRestaurantLocation(t *testing.T) {
+	lateNight := AvailabilityWindow{Weekdays: StringList{"fri"}, StartTime: "22:00", EndTime: "02:00"}
+
+	// 19:30 UTC on Friday is 22:30 in Bahrain.
+	assert.True(t, lateNight.Matches(time.Date(2019, 11, 29, 19, 30, 0, 0, time.UTC)))
+	// 23:30 UTC on Thursday is already 02:30 on Friday in Bahrain.
+	assert.False(t, lateNight.Matches(time.Date(2019, 11, 28, 23, 30, 0, 0, time.UTC)))
+
+	location := Location
+	defer func() { Location = location }()
+
+	Location = time.FixedZone("UTC-5", -5*60*60)
+
+	// 03:30 UTC on Saturday is 22:30 on Friday in UTC-5.
+	assert.True(t, lateNight.Matches(time.Date(2019, 11, 30, 3, 30, 0, 0, time.UTC)))
+	assert.False(t, lateNight.Matches(time.Date(2019, 11, 29, 22, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Validate validates and normalizes block entry contacts.
+func (entry *BlockEntry) Validate() error {
+	if entry.Phone == "" && entry.Email == "" && entry.GuestID == nil {
+		return errors.New("Phone, email or guest should be specified")
+	}
+
+	if entry.Phone != "" {
+		phone, err := NormalizePhone(entry.Phone)
+		if err != nil {
+			return err
+		}
+		entry.Phone = phone
+	}
+
+	entry.Email = NormalizeEmail(entry.Email)
+
+	return nil
+}
+
+// GetAll returns list of all block entries.
+func (BlockEntry) GetAll(db *sqlx.DB) (*[]BlockEntry, error) {
+	entries := make([]BlockEntry, 0)
+
+	if err := db.Select(&entries, `SELECT * FROM blocklist ORDER BY id DESC;`); err != nil {
+		return nil, err
+	}
+
+	return &entries, nil
+}
+
+// Find returns BlockEntry object with specified ID.
+func (BlockEntry) Find(db *sqlx.DB, id uint64) (*BlockEntry, error) {
+	entry := BlockEntry{}
+
+	if err := db.Get(&entry, "SELECT * FROM blocklist WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// FindMatching returns block entry for any of reservation contacts or guest profile.
+func (BlockEntry) FindMatching(db *sqlx.DB, reservation *Reservation) (*BlockEntry, error) {
+	entry := BlockEntry{}
+
+	sql := `SELECT * FROM blocklist
+		WHERE (phone <> '' AND phone = ?) OR (email <> '' AND email = ?) OR guest_id = ?
+		LIMIT 1`
+
+	if err := db.Get(&entry, sql, reservation.Phone, reservation.Email, reservation.GuestID); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Destroy block entry with specified ID.
+func (BlockEntry) Destroy(db *sqlx.DB, id uint64) error {
+
+	if _, err := BlockEntry.Find(BlockEntry{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM blocklist WHERE id = ?;`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update block entry object in DB.
+func (entry *BlockEntry) Update(db *sqlx.DB) error {
+
+	if _, err := BlockEntry.Find(BlockEntry{}, db, entry.ID); err != nil {
+		return err
+	}
+
+	query := `UPDATE blocklist SET phone=:phone, email=:email, guest_id=:guest_id, reason=:reason WHERE id = :id`
+	_, err := db.NamedExec(query, entry)
+
+	if err != nil {
+		return err
+	}
+
+	updatedEntry, err := BlockEntry.Find(BlockEntry{}, db, entry.ID)
+	if err != nil {
+		return err
+	}
+	*entry = *updatedEntry
+
+	return nil
+}
+
+// Insert adds new block entry.
+func (entry *BlockEntry) Insert(db *sqlx.DB) error {
+	sqlStatement := `INSERT INTO blocklist (phone, email, guest_id, reason) VALUES (?, ?, ?, ?);`
+
+	result, err := db.Exec(sqlStatement, entry.Phone, entry.Email, entry.GuestID, entry.Reason)
+
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	createdEntry, err := BlockEntry.Find(BlockEntry{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*entry = *createdEntry
+
+	return nil
+}
+
+// CountNoShows returns number of no-shows for reservation contacts or guest profile in specified period.
+func (reservation *Reservation) CountNoShows(db *sqlx.DB, policy NoShowPolicy) (int64, error) {
+	var count int64
+
+	sql := `SELECT COUNT(*) FROM reservations
+		WHERE state = ? AND time >= NOW() - INTERVAL ? SECOND AND (phone = ? OR email = ? OR guest_id = ?)`
+
+	err := db.Get(&count, sql,
+		StateNoShow,
+		int64(policy.Window.Seconds()),
+		reservation.Phone,
+		reservation.Email,
+		reservation.GuestID,
+	)
+
+	return count, err
+}
+
+// CheckRestrictions returns restriction for reservation, when contact is blocked
+// or has too many no-shows. Returns nil, when booking is allowed.
+// Reservation contacts should be already normalized by Validate.
+func (reservation *Reservation) CheckRestrictions(db *sqlx.DB, policy NoShowPolicy) (*Restriction, error) {
+	_, err := BlockEntry.FindMatching(BlockEntry{}, db, reservation)
+
+	if err == nil {
+		// Reason of the entry is a staff note, it is returned only by blocklist endpoints.
+		return &Restriction{Action: RestrictionReject, Reason: "Booking is not allowed for this contact"}, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if policy.Limit <= 0 {
+		return nil, nil
+	}
+
+	noShows, err := reservation.CountNoShows(db, policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if noShows < policy.Limit {
+		return nil, nil
+	}
+
+	days := int64(policy.Window.Hours() / 24)
+	restriction := Restriction{Action: policy.Action}
+
+	if policy.Action == RestrictionDeposit {
+		restriction.Reason = fmt.Sprintf("Deposit is required after %d no-shows in last %d days", noShows, days)
+	} else {
+		restriction.Action = RestrictionReject
+		restriction.Reason = fmt.Sprintf("Booking is not allowed after %d no-shows in last %d days", noShows, days)
+	}
+
+	return &restriction, nil
+}
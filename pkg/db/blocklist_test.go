@@ -0,0 +1,107 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var blocklistColumns = []string{"id", "phone", "email", "guest_id", "reason"}
+
+func TestCheckRestrictions(t *testing.T) {
+	policy := NoShowPolicy{Limit: 3, Window: 90 * 24 * time.Hour, Action: RestrictionReject}
+	guestID := uint64(5)
+	reservation := Reservation{Email: "guest@example.com", Phone: "+97336123456", GuestID: &guestID}
+
+	tests := []struct {
+		name     string
+		policy   NoShowPolicy
+		blocked  *sqlmock.Rows
+		noShows  int64
+		expected *Restriction
+	}{
+		{
+			name:     "blocked contact",
+			policy:   policy,
+			blocked:  sqlmock.NewRows(blocklistColumns).AddRow(1, "+97336123456", "", nil, "Fraud"),
+			expected: &Restriction{Action: RestrictionReject, Reason: "Booking is not allowed for this contact"},
+		},
+		{
+			name:     "blocked without reason",
+			policy:   policy,
+			blocked:  sqlmock.NewRows(blocklistColumns).AddRow(1, "", "", 5, ""),
+			expected: &Restriction{Action: RestrictionReject, Reason: "Booking is not allowed for this contact"},
+		},
+		{
+			name:     "few no-shows",
+			policy:   policy,
+			noShows:  2,
+			expected: nil,
+		},
+		{
+			name:     "no-show limit rejects",
+			policy:   policy,
+			noShows:  3,
+			expected: &Restriction{Action: RestrictionReject, Reason: "Booking is not allowed after 3 no-shows in last 90 days"},
+		},
+		{
+			name:     "no-show limit requires deposit",
+			policy:   NoShowPolicy{Limit: 3, Window: 90 * 24 * time.Hour, Action: RestrictionDeposit},
+			noShows:  4,
+			expected: &Restriction{Action: RestrictionDeposit, Reason: "Deposit is required after 4 no-shows in last 90 days"},
+		},
+		{
+			name:     "no-show limit disabled",
+			policy:   NoShowPolicy{Limit: 0},
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		blocked := test.blocked
+		if blocked == nil {
+			blocked = sqlmock.NewRows(blocklistColumns)
+		}
+
+		mock.ExpectQuery("^SELECT (.+) FROM blocklist").
+			WithArgs("+97336123456", "guest@example.com", 5).
+			WillReturnRows(blocked)
+
+		// No-shows are counted only for contacts, which are not blocked.
+		if test.blocked == nil && test.policy.Limit > 0 {
+			mock.ExpectQuery("^SELECT COUNT\\(\\*\\) FROM reservations").
+				WithArgs(StateNoShow, int64(90*24*60*60), "+97336123456", "guest@example.com", 5).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(test.noShows))
+		}
+
+		restriction, err := reservation.CheckRestrictions(DB, test.policy)
+
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, restriction, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
+
+func TestValidateBlockEntry(t *testing.T) {
+	entry := BlockEntry{Phone: "3612 3456", Email: " Guest@Example.com "}
+
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, "+97336123456", entry.Phone)
+	assert.Equal(t, "guest@example.com", entry.Email)
+
+	assert.Error(t, (&BlockEntry{}).Validate())
+	assert.Error(t, (&BlockEntry{Phone: "123"}).Validate())
+}
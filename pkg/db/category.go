@@ -1,14 +1,63 @@
 package db
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 )
 
 import (
 	"github.com/jmoiron/sqlx"
 )
 
-// GetAll returns list menu categories sorted by order.
+// ErrCategoryOrderTaken is returned, when other category has the same order.
+var ErrCategoryOrderTaken = errors.New("item with such ordinal number already exists, change order")
+
+// ErrCategoryNotEmpty is returned, when deleted category has menu items, which are not moved to other category.
+var ErrCategoryNotEmpty = errors.New("Category has menu items, move them to other category")
+
+// Validate validates and normalizes menu category fields.
+func (menuCategory *MenuCategory) Validate() error {
+	errs := ValidationErrors{}
+
+	menuCategory.Name = strings.TrimSpace(menuCategory.Name)
+	errs.check(len(menuCategory.Name) != 0, "name", "Name should not be empty")
+	validateAvailability(&errs, menuCategory.Availability)
+
+	return errs.err()
+}
+
+// Checks, that parent category exists and is not the category itself or its sub-category.
+func (menuCategory *MenuCategory) checkParent(tx *sqlx.Tx) error {
+	if menuCategory.ParentID == nil {
+		return nil
+	}
+
+	for id := *menuCategory.ParentID; ; {
+		if id == menuCategory.ID {
+			return ValidationErrors{{Field: "parent_id", Error: "Category could not be nested into itself or its sub-category"}}
+		}
+
+		var parentID *uint64
+
+		if err := tx.Get(&parentID, `SELECT parent_id FROM categories WHERE id = ?`, id); err != nil {
+			if err == sql.ErrNoRows {
+				return ValidationErrors{{Field: "parent_id", Error: fmt.Sprintf("Invalid parent category id %d", id)}}
+			}
+
+			return err
+		}
+
+		if parentID == nil {
+			return nil
+		}
+
+		id = *parentID
+	}
+}
+
+// GetAll returns list menu categories with their availability sorted by order.
 func (MenuCategory) GetAll(db *sqlx.DB) (*[]MenuCategory, error) {
 	categories := make([]MenuCategory, 0)
 
@@ -16,33 +65,62 @@ func (MenuCategory) GetAll(db *sqlx.DB) (*[]MenuCategory, error) {
 		return nil, err
 	}
 
+	if err := loadCategoryAvailability(db, categories); err != nil {
+		return nil, err
+	}
+
 	return &categories, nil
 }
 
-// Find returns MenuItem object with specified ID.
+// Find returns MenuCategory object with specified ID and its availability.
 func (MenuCategory) Find(db *sqlx.DB, id uint64) (*MenuCategory, error) {
-	category := MenuCategory{}
+	categories := make([]MenuCategory, 1)
 
-	if err := db.Get(&category, "SELECT * FROM categories WHERE id = ?", id); err != nil {
+	if err := db.Get(&categories[0], "SELECT * FROM categories WHERE id = ?", id); err != nil {
 		return nil, err
 	}
 
-	return &category, nil
+	if err := loadCategoryAvailability(db, categories); err != nil {
+		return nil, err
+	}
+
+	return &categories[0], nil
 }
 
-// Insert adds new category.
+// Insert adds new category with its availability.
 func (menuCategory *MenuCategory) Insert(db *sqlx.DB) error {
-	sqlStatement := "INSERT INTO categories (name, `order`) VALUES (?, ?);"
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := menuCategory.checkParent(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	sqlStatement := "INSERT INTO categories (name, `order`, parent_id) VALUES (?, ?, ?);"
 
-	result, err := db.Exec(sqlStatement, menuCategory.Name, menuCategory.Order)
+	result, err := tx.Exec(sqlStatement, menuCategory.Name, menuCategory.Order, menuCategory.ParentID)
 
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	id, err := result.LastInsertId()
 
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveAvailability(tx, "category_id", uint64(id), menuCategory.Availability); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
@@ -55,7 +133,7 @@ func (menuCategory *MenuCategory) Insert(db *sqlx.DB) error {
 	return nil
 }
 
-// Update menu category object in DB.
+// Update menu category object and its availability in DB.
 func (menuCategory *MenuCategory) Update(db *sqlx.DB) error {
 
 	if _, err := MenuCategory.Find(MenuCategory{}, db, menuCategory.ID); err != nil {
@@ -66,15 +144,140 @@ func (menuCategory *MenuCategory) Update(db *sqlx.DB) error {
 	existingCategory := MenuCategory{}
 	err := db.Get(&existingCategory, "SELECT * FROM categories WHERE `order` = ?", menuCategory.Order)
 	if err == nil && existingCategory.ID != menuCategory.ID {
-		return errors.New("item with such ordinal number already exists, change order")
+		return ErrCategoryOrderTaken
 	}
 
-	query := "UPDATE categories SET name=:name, `order`=:order WHERE id=:id"
-	_, err = db.NamedExec(query, menuCategory)
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := menuCategory.checkParent(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := "UPDATE categories SET name=:name, `order`=:order, parent_id=:parent_id WHERE id=:id"
+	_, err = tx.NamedExec(query, menuCategory)
 
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
+	if err := saveAvailability(tx, "category_id", menuCategory.ID, menuCategory.Availability); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	updatedCategory, err := MenuCategory.Find(MenuCategory{}, db, menuCategory.ID)
+	if err != nil {
+		return err
+	}
+	*menuCategory = *updatedCategory
+
 	return nil
 }
+
+// Destroy menu category with specified ID. Menu items of the category are moved to category
+// with moveItemsTo ID, ErrCategoryNotEmpty is returned, when category has items and it is not specified.
+// Sub-categories are moved to parent of deleted category.
+func (MenuCategory) Destroy(db *sqlx.DB, id uint64, moveItemsTo *uint64) error {
+	category, err := MenuCategory.Find(MenuCategory{}, db, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if moveItemsTo != nil {
+		invalid := ValidationErrors{{Field: "move_items_to", Error: fmt.Sprintf("Invalid category id %d", *moveItemsTo)}}
+
+		var count int
+		if err := tx.Get(&count, `SELECT COUNT(*) FROM categories WHERE id = ?`, *moveItemsTo); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if count == 0 || *moveItemsTo == id {
+			tx.Rollback()
+			return invalid
+		}
+
+		if _, err := tx.Exec(`UPDATE menu SET category_id = ? WHERE category_id = ?`, *moveItemsTo, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else {
+		var count int
+		if err := tx.Get(&count, `SELECT COUNT(*) FROM menu WHERE category_id = ?`, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if count != 0 {
+			tx.Rollback()
+			return ErrCategoryNotEmpty
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE categories SET parent_id = ? WHERE parent_id = ?`, category.ParentID, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM categories WHERE id = ?`, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reorder sets order of all categories in one transaction, according to their position in the list.
+// List should contain ID of every category exactly once.
+func (MenuCategory) Reorder(db *sqlx.DB, ids []uint64) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	existing := make([]uint64, 0)
+	if err := tx.Select(&existing, `SELECT id FROM categories`); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	positions := make(map[uint64]int)
+	for i, id := range ids {
+		positions[id] = i
+	}
+
+	complete := len(ids) == len(existing) && len(positions) == len(ids)
+	for _, id := range existing {
+		if _, ok := positions[id]; !ok {
+			complete = false
+		}
+	}
+
+	if !complete {
+		tx.Rollback()
+		return ValidationErrors{{Field: "ids", Error: "List should contain ID of every category exactly once"}}
+	}
+
+	for i, id := range ids {
+		if _, err := tx.Exec("UPDATE categories SET `order` = ? WHERE id = ?", i+1, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
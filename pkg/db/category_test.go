@@ -0,0 +1,99 @@
+package db
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestReorderCategories(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT id FROM categories").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec("^UPDATE categories SET `order`").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^UPDATE categories SET `order`").WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, MenuCategory.Reorder(MenuCategory{}, DB, []uint64{2, 1}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderCategoriesIncompleteList(t *testing.T) {
+	lists := [][]uint64{{1}, {1, 1}, {1, 3}, {1, 2, 3}}
+
+	for _, ids := range lists {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("^SELECT id FROM categories").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectRollback()
+
+		err = MenuCategory.Reorder(MenuCategory{}, DB, ids)
+
+		assert.IsType(t, ValidationErrors{}, err, "ids %v", ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		DB.Close()
+	}
+}
+
+func TestDestroyCategoryWithItems(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM categories WHERE id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "order", "parent_id"}).AddRow(1, "Starters", 1, nil))
+	mock.ExpectQuery("^SELECT (.+) FROM availability_windows").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT COUNT(.+) FROM menu").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectRollback()
+
+	assert.Equal(t, ErrCategoryNotEmpty, MenuCategory.Destroy(MenuCategory{}, DB, 1, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestroyCategoryMovingItems(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM categories WHERE id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "order", "parent_id"}).AddRow(2, "Soups", 2, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM availability_windows").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT COUNT(.+) FROM categories").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("^UPDATE menu SET category_id").WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("^UPDATE categories SET parent_id").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("^DELETE FROM categories").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	moveItemsTo := uint64(3)
+	assert.NoError(t, MenuCategory.Destroy(MenuCategory{}, DB, 2, &moveItemsTo))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
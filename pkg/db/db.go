@@ -1,9 +1,18 @@
 package db
 
+import (
+	"time"
+)
+
 import (
 	"github.com/jmoiron/sqlx"
 )
 
+// Location is time zone of the restaurant. Times are stored in UTC,
+// but days and time of day of menu availability and reports are taken in this zone.
+// Default is Bahrain time, which has no daylight saving.
+var Location = time.FixedZone("AST", 3*60*60)
+
 // Initialize DB.
 func Initialize(connectionString string) *sqlx.DB {
 	db, err := sqlx.Open("mysql", connectionString)
@@ -0,0 +1,291 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/money"
+)
+
+// Weekday names, used in deposit rules.
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Validate validates deposit rule fields.
+func (rule *DepositRule) Validate() error {
+	if rule.MinGuests <= 0 {
+		return errors.New("Minimal number of guests should be greater than 0")
+	}
+
+	if rule.AmountPerGuest <= 0 {
+		return errors.New("Amount per guest should be greater than 0")
+	}
+
+	for i, weekday := range rule.Weekdays {
+		rule.Weekdays[i] = strings.ToLower(weekday)
+
+		if !StringList(weekdayNames).Contains(rule.Weekdays[i]) {
+			return errors.New("Weekdays are invalid, should be one of " + strings.Join(weekdayNames, ", "))
+		}
+	}
+
+	if rule.StartsOn != nil && rule.EndsOn != nil && rule.EndsOn.Before(*rule.StartsOn) {
+		return errors.New("End date should not be before start date")
+	}
+
+	return nil
+}
+
+// Matches returns true, when rule applies to reservation.
+func (rule *DepositRule) Matches(reservation *Reservation) bool {
+	if !rule.Active || reservation.Guests < rule.MinGuests {
+		return false
+	}
+
+	// Day of reservation is taken in restaurant location.
+	at := reservation.Time.In(Location)
+
+	if len(rule.Weekdays) != 0 && !rule.Weekdays.Contains(weekdayNames[at.Weekday()]) {
+		return false
+	}
+
+	// Compare dates only, rule dates are stored without time.
+	date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+
+	if rule.StartsOn != nil && date.Before(rule.StartsOn.UTC()) {
+		return false
+	}
+
+	if rule.EndsOn != nil && date.After(rule.EndsOn.UTC()) {
+		return false
+	}
+
+	return true
+}
+
+// RequiredDeposit returns deposit amount for reservation, using the most expensive matching rule.
+// Returns 0, when deposit is not required.
+func (DepositRule) RequiredDeposit(db *sqlx.DB, reservation *Reservation) (money.Money, error) {
+	rules, err := DepositRule.GetAll(DepositRule{}, db)
+
+	if err != nil {
+		return 0, err
+	}
+
+	amount := money.Money(0)
+
+	for _, rule := range *rules {
+		if ruleAmount := rule.AmountPerGuest.Mul(reservation.Guests); rule.Matches(reservation) && ruleAmount > amount {
+			amount = ruleAmount
+		}
+	}
+
+	return amount, nil
+}
+
+// GetAll returns list of all deposit rules.
+func (DepositRule) GetAll(db *sqlx.DB) (*[]DepositRule, error) {
+	rules := make([]DepositRule, 0)
+
+	if err := db.Select(&rules, `SELECT * FROM deposit_rules;`); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// Find returns DepositRule object with specified ID.
+func (DepositRule) Find(db *sqlx.DB, id uint64) (*DepositRule, error) {
+	rule := DepositRule{}
+
+	if err := db.Get(&rule, "SELECT * FROM deposit_rules WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// Destroy deposit rule with specified ID.
+func (DepositRule) Destroy(db *sqlx.DB, id uint64) error {
+
+	if _, err := DepositRule.Find(DepositRule{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM deposit_rules WHERE id = ?;`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update deposit rule object in DB.
+func (rule *DepositRule) Update(db *sqlx.DB) error {
+
+	if _, err := DepositRule.Find(DepositRule{}, db, rule.ID); err != nil {
+		return err
+	}
+
+	query := `UPDATE deposit_rules SET
+		min_guests=:min_guests, weekdays=:weekdays, starts_on=:starts_on, ends_on=:ends_on,
+		amount_per_guest=:amount_per_guest, active=:active
+		WHERE id = :id`
+	_, err := db.NamedExec(query, rule)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Insert adds new deposit rule.
+func (rule *DepositRule) Insert(db *sqlx.DB) error {
+	sqlStatement := `INSERT INTO deposit_rules
+		(min_guests, weekdays, starts_on, ends_on, amount_per_guest, active) VALUES (?, ?, ?, ?, ?, ?);`
+
+	result, err := db.Exec(sqlStatement,
+		rule.MinGuests,
+		rule.Weekdays,
+		rule.StartsOn,
+		rule.EndsOn,
+		rule.AmountPerGuest,
+		rule.Active,
+	)
+
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	createdRule, err := DepositRule.Find(DepositRule{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*rule = *createdRule
+
+	return nil
+}
+
+// FindByReservation returns deposit of reservation.
+func (Deposit) FindByReservation(db *sqlx.DB, reservationID uint64) (*Deposit, error) {
+	deposit := Deposit{}
+
+	if err := db.Get(&deposit, "SELECT * FROM deposits WHERE reservation_id = ?", reservationID); err != nil {
+		return nil, err
+	}
+
+	return &deposit, nil
+}
+
+// Insert adds new deposit.
+func (deposit *Deposit) Insert(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	if err := deposit.insert(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (deposit *Deposit) insert(tx *sqlx.Tx) error {
+	sqlStatement := `INSERT INTO deposits (reservation_id, amount, status, reason, provider_ref) VALUES (?, ?, ?, ?, ?);`
+
+	_, err := tx.Exec(sqlStatement,
+		deposit.ReservationID,
+		deposit.Amount,
+		deposit.Status,
+		deposit.Reason,
+		deposit.ProviderRef,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	createdDeposit := Deposit{}
+
+	if err := tx.Get(&createdDeposit, "SELECT * FROM deposits WHERE reservation_id = ?", deposit.ReservationID); err != nil {
+		return err
+	}
+	*deposit = createdDeposit
+
+	return nil
+}
+
+// Update saves status and payment reference of deposit.
+func (deposit *Deposit) Update(db *sqlx.DB) error {
+	query := `UPDATE deposits SET status=:status, provider_ref=:provider_ref WHERE id = :id`
+
+	if _, err := db.NamedExec(query, deposit); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ErrDepositClaimed is returned, when payment of deposit was already started by other request.
+var ErrDepositClaimed = errors.New("Deposit could not be paid, its payment was already started")
+
+// Claim moves required deposit to pending status before payment is started,
+// so concurrent requests could not charge the same deposit twice.
+func (deposit *Deposit) Claim(db *sqlx.DB) error {
+	sql := `UPDATE deposits SET status = ? WHERE id = ? AND status = ?`
+
+	result, err := db.Exec(sql, DepositPending, deposit.ID, DepositRequired)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrDepositClaimed
+	}
+
+	deposit.Status = DepositPending
+
+	return nil
+}
+
+// Release returns claimed deposit to required status, when payment has failed.
+func (deposit *Deposit) Release(db *sqlx.DB) error {
+	sql := `UPDATE deposits SET status = ? WHERE id = ? AND status = ?`
+
+	if _, err := db.Exec(sql, DepositRequired, deposit.ID, DepositPending); err != nil {
+		return err
+	}
+
+	deposit.Status = DepositRequired
+
+	return nil
+}
+
+// Forfeit keeps paid deposit of reservation after no-show.
+func (Deposit) Forfeit(db *sqlx.DB, reservationID uint64) error {
+	sql := `UPDATE deposits SET status = ? WHERE reservation_id = ? AND status = ?`
+
+	if _, err := db.Exec(sql, DepositForfeited, reservationID, DepositPaid); err != nil {
+		return err
+	}
+
+	return nil
+}
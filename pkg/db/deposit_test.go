@@ -0,0 +1,81 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepositRuleMatches(t *testing.T) {
+	// 2019-11-29 is Friday.
+	friday := time.Date(2019, 11, 29, 20, 0, 0, 0, time.UTC)
+	fridayDate := time.Date(2019, 11, 29, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	// 22:00 UTC on Friday is 01:00 on Saturday in Bahrain.
+	lateFriday := time.Date(2019, 11, 29, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		rule        DepositRule
+		reservation Reservation
+		expected    bool
+	}{
+		{
+			name:        "large party",
+			rule:        DepositRule{MinGuests: 6, Active: true},
+			reservation: Reservation{Guests: 8, Time: friday},
+			expected:    true,
+		},
+		{
+			name:        "small party",
+			rule:        DepositRule{MinGuests: 6, Active: true},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    false,
+		},
+		{
+			name:        "inactive rule",
+			rule:        DepositRule{MinGuests: 1, Active: false},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    false,
+		},
+		{
+			name:        "matching weekday",
+			rule:        DepositRule{MinGuests: 1, Weekdays: StringList{"fri", "sat"}, Active: true},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    true,
+		},
+		{
+			name:        "other weekday",
+			rule:        DepositRule{MinGuests: 1, Weekdays: StringList{"sat"}, Active: true},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    false,
+		},
+		{
+			name:        "weekday in restaurant location",
+			rule:        DepositRule{MinGuests: 1, Weekdays: StringList{"sat"}, Active: true},
+			reservation: Reservation{Guests: 2, Time: lateFriday},
+			expected:    true,
+		},
+		{
+			name:        "before start date",
+			rule:        DepositRule{MinGuests: 1, StartsOn: &december, Active: true},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    false,
+		},
+		{
+			name:        "on end date",
+			rule:        DepositRule{MinGuests: 1, EndsOn: &fridayDate, Active: true},
+			reservation: Reservation{Guests: 2, Time: friday},
+			expected:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.rule.Matches(&test.reservation))
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+var tagCodeRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate validates and normalizes dietary tag fields.
+func (tag *DietaryTag) Validate() error {
+	errs := ValidationErrors{}
+
+	tag.Code = strings.ToLower(strings.TrimSpace(tag.Code))
+	errs.check(tagCodeRegexp.MatchString(tag.Code), "code",
+		"Code should contain only latin letters, digits and dashes, e.g. gluten-free")
+
+	tag.Name = strings.TrimSpace(tag.Name)
+	errs.check(len(tag.Name) != 0, "name", "Name should not be empty")
+
+	errs.check(tag.Kind == TagKindAllergen || tag.Kind == TagKindDiet || tag.Kind == TagKindSpice, "kind",
+		"Kind is invalid, should be allergen, diet or spice")
+
+	return errs.err()
+}
+
+// GetAll returns list of all dietary tags, ordered by kind and code.
+func (DietaryTag) GetAll(db *sqlx.DB) (*[]DietaryTag, error) {
+	tags := make([]DietaryTag, 0)
+
+	if err := db.Select(&tags, `SELECT * FROM dietary_tags ORDER BY kind, code`); err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
+
+// Find returns DietaryTag object with specified ID.
+func (DietaryTag) Find(db *sqlx.DB, id uint64) (*DietaryTag, error) {
+	tag := DietaryTag{}
+
+	if err := db.Get(&tag, `SELECT * FROM dietary_tags WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Destroy dietary tag with specified ID, it is removed from all menu items.
+func (DietaryTag) Destroy(db *sqlx.DB, id uint64) error {
+	if _, err := DietaryTag.Find(DietaryTag{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM dietary_tags WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update dietary tag object in DB.
+func (tag *DietaryTag) Update(db *sqlx.DB) error {
+	if _, err := DietaryTag.Find(DietaryTag{}, db, tag.ID); err != nil {
+		return err
+	}
+
+	if _, err := db.NamedExec(`UPDATE dietary_tags SET code=:code, name=:name, kind=:kind WHERE id=:id`, tag); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Insert adds new dietary tag.
+func (tag *DietaryTag) Insert(db *sqlx.DB) error {
+	result, err := db.NamedExec(`INSERT INTO dietary_tags (code, name, kind) VALUES (:code, :name, :kind)`, tag)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	createdTag, err := DietaryTag.Find(DietaryTag{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*tag = *createdTag
+
+	return nil
+}
+
+// Loads tag codes for every menu item in the list.
+func loadMenuTags(db *sqlx.DB, menuItems []MenuItem) error {
+	for i := range menuItems {
+		menuItems[i].Tags = make([]string, 0)
+	}
+
+	if len(menuItems) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(menuItems))
+	index := make(map[uint64]int)
+	for i, menuItem := range menuItems {
+		ids[i] = menuItem.ID
+		index[menuItem.ID] = i
+	}
+
+	query, args, err := sqlx.In(`SELECT menu_tags.menu_item_id, dietary_tags.code FROM menu_tags
+		JOIN dietary_tags ON dietary_tags.id = menu_tags.tag_id
+		WHERE menu_tags.menu_item_id IN (?) ORDER BY dietary_tags.kind, dietary_tags.code`, ids)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]struct {
+		MenuItemID uint64 `db:"menu_item_id"`
+		Code       string `db:"code"`
+	}, 0)
+
+	if err := db.Select(&tags, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		menuItem := &menuItems[index[tag.MenuItemID]]
+		menuItem.Tags = append(menuItem.Tags, tag.Code)
+	}
+
+	return nil
+}
+
+// Replaces tags of menu item. Error with "tags" field is returned for unknown tag codes.
+func (menuItem *MenuItem) saveTags(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM menu_tags WHERE menu_item_id = ?`, menuItem.ID); err != nil {
+		return err
+	}
+
+	if len(menuItem.Tags) == 0 {
+		return nil
+	}
+
+	for i, code := range menuItem.Tags {
+		menuItem.Tags[i] = strings.ToLower(strings.TrimSpace(code))
+	}
+
+	query, args, err := sqlx.In(`SELECT * FROM dietary_tags WHERE code IN (?)`, menuItem.Tags)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]DietaryTag, 0)
+	if err := tx.Select(&tags, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	ids := make(map[string]uint64)
+	for _, tag := range tags {
+		ids[tag.Code] = tag.ID
+	}
+
+	for _, code := range menuItem.Tags {
+		if _, ok := ids[code]; !ok {
+			return ValidationErrors{{Field: "tags", Error: fmt.Sprintf("Unknown tag %s", code)}}
+		}
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec(`INSERT INTO menu_tags (menu_item_id, tag_id) VALUES (?, ?)`, menuItem.ID, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MenuFilter filters menu items by dietary tags.
+type MenuFilter struct {
+	// Codes of tags, which every item should have, e.g. "vegan".
+	Tags []string
+	// Codes of allergens, which items should not contain, e.g. "nuts".
+	ExcludeAllergens []string
+}
+
+// IsEmpty checks, whether filter matches all menu items.
+func (filter MenuFilter) IsEmpty() bool {
+	return len(filter.Tags) == 0 && len(filter.ExcludeAllergens) == 0
+}
+
+// Matches checks, whether menu item with loaded tags matches the filter.
+func (filter MenuFilter) Matches(menuItem MenuItem) bool {
+	tags := StringList(menuItem.Tags)
+
+	for _, tag := range filter.Tags {
+		if !tags.Contains(tag) {
+			return false
+		}
+	}
+
+	for _, allergen := range filter.ExcludeAllergens {
+		if tags.Contains(allergen) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply returns menu items, which match the filter.
+func (filter MenuFilter) Apply(menuItems []MenuItem) []MenuItem {
+	if filter.IsEmpty() {
+		return menuItems
+	}
+
+	filtered := make([]MenuItem, 0)
+
+	for _, menuItem := range menuItems {
+		if filter.Matches(menuItem) {
+			filtered = append(filtered, menuItem)
+		}
+	}
+
+	return filtered
+}
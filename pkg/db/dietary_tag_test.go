@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMenuFilter(t *testing.T) {
+	menu := []MenuItem{
+		{ID: 1, Name: "Falafel", Tags: []string{"sesame", "vegan"}},
+		{ID: 2, Name: "Baklava", Tags: []string{"gluten", "nuts", "vegetarian"}},
+		{ID: 3, Name: "Shawarma", Tags: []string{"halal", "spicy"}},
+		{ID: 4, Name: "Lemonade", Tags: []string{}},
+	}
+
+	tests := []struct {
+		name     string
+		filter   MenuFilter
+		expected []uint64
+	}{
+		{"empty filter", MenuFilter{}, []uint64{1, 2, 3, 4}},
+		{"tag", MenuFilter{Tags: []string{"vegan"}}, []uint64{1}},
+		{"all tags are required", MenuFilter{Tags: []string{"halal", "vegan"}}, []uint64{}},
+		{"excluded allergen", MenuFilter{ExcludeAllergens: []string{"nuts"}}, []uint64{1, 3, 4}},
+		{"excluded allergens", MenuFilter{ExcludeAllergens: []string{"nuts", "sesame"}}, []uint64{3, 4}},
+		{"tag and excluded allergen", MenuFilter{Tags: []string{"vegetarian"}, ExcludeAllergens: []string{"gluten"}}, []uint64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids := make([]uint64, 0)
+			for _, menuItem := range test.filter.Apply(menu) {
+				ids = append(ids, menuItem.ID)
+			}
+
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// Special needs, which reservations could be filtered by.
+const (
+	NeedAccessibility = "accessibility"
+	NeedChildren      = "children"
+	NeedHighChairs    = "high_chairs"
+	NeedNotes         = "notes"
+)
+
+// Conditions of special needs.
+var needConditions = map[string]string{
+	NeedAccessibility: "accessibility_needs <> ''",
+	NeedChildren:      "children > 0",
+	NeedHighChairs:    "high_chairs > 0",
+	NeedNotes:         "notes <> ''",
+}
+
+// IsValidNeed returns true for supported special need.
+func IsValidNeed(need string) bool {
+	_, ok := needConditions[need]
+	return ok
+}
+
+// ReservationFilter contains conditions to list reservations.
+// Empty fields are not used.
+type ReservationFilter struct {
+	// List only reservations, which have not started yet.
+	Upcoming bool
+	// Reservations starting from this time.
+	From *time.Time
+	// Reservations starting before this time.
+	To       *time.Time
+	State    State
+	TableID  uint64
+	Occasion Occasion
+	// Reservations, which have all specified special needs.
+	Needs []string
+}
+
+// Returns SQL WHERE clause with arguments for the filter.
+func (filter ReservationFilter) where() (string, []interface{}) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if filter.Upcoming {
+		conditions = append(conditions, "time >= NOW()")
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, *filter.From)
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "time < ?")
+		args = append(args, *filter.To)
+	}
+
+	if filter.State != "" {
+		conditions = append(conditions, "state = ?")
+		args = append(args, filter.State)
+	}
+
+	if filter.TableID != 0 {
+		conditions = append(conditions, "table_id = ?")
+		args = append(args, filter.TableID)
+	}
+
+	if filter.Occasion != "" {
+		conditions = append(conditions, "occasion = ?")
+		args = append(args, filter.Occasion)
+	}
+
+	for _, need := range filter.Needs {
+		if condition, ok := needConditions[need]; ok {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
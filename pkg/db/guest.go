@@ -0,0 +1,329 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/tools"
+	"github.com/ttacon/libphonenumber"
+)
+
+// NormalizeEmail returns email in form, used to compare contacts.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone validates phone number and formats it to E164 format.
+// Numbers without country code are considered to be Bahraini.
+func NormalizePhone(phone string) (string, error) {
+	phoneNumber, err := libphonenumber.Parse(phone, "BH")
+	if err != nil || !libphonenumber.IsValidNumber(phoneNumber) {
+		return "", errors.New("Phone is invalid")
+	}
+
+	return libphonenumber.Format(phoneNumber, libphonenumber.E164), nil
+}
+
+// Validate validates and normalizes guest fields.
+// Phone is normalized the same way as reservation phone, so guests are matched by contacts.
+func (guest *Guest) Validate() error {
+	errs := ValidationErrors{}
+
+	guest.FullName = strings.TrimSpace(guest.FullName)
+	errs.check(len(guest.FullName) != 0, "full_name", "Full Name is invalid")
+
+	guest.Email = NormalizeEmail(guest.Email)
+	errs.check(guest.Email == "" || tools.ValidateEmail(guest.Email), "email", "Email is invalid")
+
+	if guest.Phone != "" {
+		phone, err := NormalizePhone(guest.Phone)
+		errs.check(err == nil, "phone", "Phone is invalid")
+		if err == nil {
+			guest.Phone = phone
+		}
+	}
+
+	for i, tag := range guest.Tags {
+		guest.Tags[i] = strings.ToLower(strings.TrimSpace(tag))
+		errs.check(guest.Tags[i] != "" && !strings.Contains(guest.Tags[i], ","), "tags",
+			"Tags should not be empty or contain commas")
+	}
+
+	return errs.err()
+}
+
+// GetAll returns list of guests, optionally filtered by name, email or phone.
+func (Guest) GetAll(db *sqlx.DB, search string) (*[]Guest, error) {
+	guests := make([]Guest, 0)
+
+	if search == "" {
+		if err := db.Select(&guests, `SELECT * FROM guests ORDER BY full_name;`); err != nil {
+			return nil, err
+		}
+
+		return &guests, nil
+	}
+
+	pattern := "%" + search + "%"
+	sql := `SELECT * FROM guests WHERE full_name LIKE ? OR email LIKE ? OR phone LIKE ? ORDER BY full_name`
+
+	if err := db.Select(&guests, sql, pattern, pattern, pattern); err != nil {
+		return nil, err
+	}
+
+	return &guests, nil
+}
+
+// Find returns Guest object with specified ID.
+func (Guest) Find(db *sqlx.DB, id uint64) (*Guest, error) {
+	guest := Guest{}
+
+	if err := db.Get(&guest, "SELECT * FROM guests WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return &guest, nil
+}
+
+// FindByContact returns guest with the same phone or email, phone match is preferred.
+func (Guest) FindByContact(db *sqlx.DB, email, phone string) (*Guest, error) {
+	return findGuestByContact(db, email, phone)
+}
+
+func findGuestByContact(queryer sqlx.Queryer, email, phone string) (*Guest, error) {
+	guest := Guest{}
+
+	sql := `SELECT * FROM guests WHERE (phone = ? AND phone <> '') OR (email = ? AND email <> '')
+		ORDER BY phone = ? DESC, id ASC LIMIT 1`
+
+	if err := sqlx.Get(queryer, &guest, sql, phone, email, phone); err != nil {
+		return nil, err
+	}
+
+	return &guest, nil
+}
+
+// MatchGuest links reservation to existing guest profile with the same contacts.
+// Unlike LinkGuest, new profile is not created.
+func (reservation *Reservation) MatchGuest(db *sqlx.DB) error {
+	guest, err := Guest.FindByContact(Guest{}, db, reservation.Email, reservation.Phone)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	reservation.GuestID = &guest.ID
+
+	return nil
+}
+
+// LinkGuest finds guest profile by reservation contacts or creates new one.
+// Reservation contacts should be already normalized by Validate.
+func (reservation *Reservation) LinkGuest(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	if err := reservation.linkGuest(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (reservation *Reservation) linkGuest(tx *sqlx.Tx) error {
+	guest, err := findGuestByContact(tx, reservation.Email, reservation.Phone)
+
+	if err == sql.ErrNoRows {
+		guest = &Guest{
+			FullName: reservation.FullName,
+			Email:    reservation.Email,
+			Phone:    reservation.Phone,
+		}
+
+		err = guest.insert(tx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	reservation.GuestID = &guest.ID
+
+	return nil
+}
+
+// Insert adds new guest.
+func (guest *Guest) Insert(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	if err := guest.insert(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (guest *Guest) insert(tx *sqlx.Tx) error {
+	sqlStatement := `INSERT INTO guests (full_name, email, phone, notes, tags) VALUES (?, ?, ?, ?, ?);`
+
+	result, err := tx.Exec(sqlStatement, guest.FullName, guest.Email, guest.Phone, guest.Notes, guest.Tags)
+
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	createdGuest := Guest{}
+
+	if err := tx.Get(&createdGuest, "SELECT * FROM guests WHERE id = ?", id); err != nil {
+		return err
+	}
+	*guest = createdGuest
+
+	return nil
+}
+
+// Update validates guest and saves it in DB.
+func (guest *Guest) Update(db *sqlx.DB) error {
+
+	if _, err := Guest.Find(Guest{}, db, guest.ID); err != nil {
+		return err
+	}
+
+	if err := guest.Validate(); err != nil {
+		return err
+	}
+
+	query := `UPDATE guests SET full_name=:full_name, email=:email, phone=:phone, notes=:notes, tags=:tags
+		WHERE id = :id`
+	_, err := db.NamedExec(query, guest)
+
+	if err != nil {
+		return err
+	}
+
+	updatedGuest, err := Guest.Find(Guest{}, db, guest.ID)
+	if err != nil {
+		return err
+	}
+	*guest = *updatedGuest
+
+	return nil
+}
+
+// GetProfile returns guest with reservations and visit statistics.
+func (Guest) GetProfile(db *sqlx.DB, id uint64) (*GuestProfile, error) {
+	guest, err := Guest.Find(Guest{}, db, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	profile := GuestProfile{Guest: *guest, Reservations: make([]Reservation, 0)}
+
+	sql := `SELECT * FROM reservations WHERE guest_id = ? ORDER BY time DESC`
+
+	if err := db.Select(&profile.Reservations, sql, id); err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range profile.Reservations {
+		switch reservation.State {
+		case StateCompleted:
+			profile.Visits++
+		case StateNoShow:
+			profile.NoShows++
+		case StateCancelled:
+			profile.Cancellations++
+		}
+	}
+
+	return &profile, nil
+}
+
+// Merge moves reservations, series, block entries, notes and tags of source guest into guest and deletes source.
+func (guest *Guest) Merge(db *sqlx.DB, sourceID uint64) error {
+	if guest.ID == sourceID {
+		return errors.New("Guest could not be merged with itself")
+	}
+
+	source, err := Guest.Find(Guest{}, db, sourceID)
+
+	if err != nil {
+		return err
+	}
+
+	if guest.Email == "" {
+		guest.Email = source.Email
+	}
+
+	if guest.Phone == "" {
+		guest.Phone = source.Phone
+	}
+
+	if source.Notes != "" {
+		guest.Notes = strings.TrimSpace(guest.Notes + "\n" + source.Notes)
+	}
+
+	for _, tag := range source.Tags {
+		if !guest.Tags.Contains(tag) {
+			guest.Tags = append(guest.Tags, tag)
+		}
+	}
+
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE reservations SET guest_id = ? WHERE guest_id = ?`, guest.ID, source.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE reservation_series SET guest_id = ? WHERE guest_id = ?`, guest.ID, source.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Block entries are moved, otherwise they are deleted with source and blocked guest is unblocked.
+	if _, err := tx.Exec(`UPDATE blocklist SET guest_id = ? WHERE guest_id = ?`, guest.ID, source.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `UPDATE guests SET email=:email, phone=:phone, notes=:notes, tags=:tags WHERE id = :id`
+	if _, err := tx.NamedExec(query, guest); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM guests WHERE id = ?`, source.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var guestColumns = []string{"id", "full_name", "email", "phone", "notes", "tags"}
+
+func TestLinkGuestMatchesExistingProfile(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE \\(phone = \\? AND phone <> ''\\) OR \\(email = \\? AND email <> ''\\)").
+		WithArgs("+97312345678", "guest@example.com", "+97312345678").
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(5, "Guest", "", "+97312345678", "", ""))
+	mock.ExpectCommit()
+
+	reservation := Reservation{FullName: "Guest", Email: "guest@example.com", Phone: "+97312345678"}
+
+	assert.NoError(t, reservation.LinkGuest(DB))
+	assert.Equal(t, uint64(5), *reservation.GuestID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLinkGuestCreatesProfile(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE").WillReturnRows(sqlmock.NewRows(guestColumns))
+	mock.ExpectExec("^INSERT INTO guests").
+		WithArgs("Guest", "guest@example.com", "+97312345678", "", StringList(nil)).
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE id").
+		WithArgs(6).
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(6, "Guest", "guest@example.com", "+97312345678", "", ""))
+	mock.ExpectCommit()
+
+	reservation := Reservation{FullName: "Guest", Email: "guest@example.com", Phone: "+97312345678"}
+
+	assert.NoError(t, reservation.LinkGuest(DB))
+	assert.Equal(t, uint64(6), *reservation.GuestID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMatchGuestDoesNotCreateProfile(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE").WillReturnRows(sqlmock.NewRows(guestColumns))
+
+	reservation := Reservation{FullName: "Guest", Email: "guest@example.com", Phone: "+97312345678"}
+
+	assert.NoError(t, reservation.MatchGuest(DB))
+	assert.Nil(t, reservation.GuestID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateGuest(t *testing.T) {
+	guest := Guest{FullName: " Guest ", Email: " Guest@Example.com", Phone: "3612 3456", Tags: StringList{" VIP "}}
+
+	assert.NoError(t, guest.Validate())
+	assert.Equal(t, "Guest", guest.FullName)
+	assert.Equal(t, "guest@example.com", guest.Email)
+	assert.Equal(t, "+97336123456", guest.Phone)
+	assert.Equal(t, StringList{"vip"}, guest.Tags)
+
+	invalid := Guest{FullName: "", Email: "guest", Phone: "123", Tags: StringList{"a,b"}}
+	err := invalid.Validate()
+
+	assert.Equal(t, ValidationErrors{
+		{Field: "full_name", Error: "Full Name is invalid"},
+		{Field: "email", Error: "Email is invalid"},
+		{Field: "phone", Error: "Phone is invalid"},
+		{Field: "tags", Error: "Tags should not be empty or contain commas"},
+	}, err)
+}
+
+func TestUpdateGuestRejectsInvalidPhone(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE id").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(5, "Guest", "", "+97336123456", "", ""))
+
+	guest := Guest{ID: 5, FullName: "Guest", Phone: "not a phone"}
+
+	assert.IsType(t, ValidationErrors{}, guest.Update(DB))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateGuestNormalizesPhone(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE id").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(5, "Guest", "", "", "", ""))
+	mock.ExpectExec("^UPDATE guests SET").
+		WithArgs("Guest", "", "+97336123456", "", StringList(nil), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE id").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(5, "Guest", "", "+97336123456", "", ""))
+
+	guest := Guest{ID: 5, FullName: "Guest", Phone: "3612 3456"}
+
+	assert.NoError(t, guest.Update(DB))
+	assert.Equal(t, "+97336123456", guest.Phone)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeGuest(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM guests WHERE id").
+		WithArgs(6).
+		WillReturnRows(sqlmock.NewRows(guestColumns).AddRow(6, "Duplicate", "guest@example.com", "", "Prefers terrace", "vip"))
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE reservations SET guest_id = \\? WHERE guest_id = \\?").
+		WithArgs(5, 6).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("^UPDATE reservation_series SET guest_id = \\? WHERE guest_id = \\?").
+		WithArgs(5, 6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^UPDATE blocklist SET guest_id = \\? WHERE guest_id = \\?").
+		WithArgs(5, 6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^UPDATE guests SET").
+		WithArgs("guest@example.com", "+97336123456", "Allergic to nuts\nPrefers terrace", StringList{"allergy-nuts", "vip"}, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^DELETE FROM guests WHERE id = \\?").
+		WithArgs(6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	guest := Guest{ID: 5, FullName: "Guest", Phone: "+97336123456", Notes: "Allergic to nuts", Tags: StringList{"allergy-nuts"}}
+
+	assert.NoError(t, guest.Merge(DB, 6))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeGuestWithItself(t *testing.T) {
+	guest := Guest{ID: 5}
+
+	assert.Error(t, guest.Merge(nil, 5))
+}
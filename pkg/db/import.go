@@ -0,0 +1,128 @@
+package db
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// ImportRowError is error of single row of import batch.
+type ImportRowError struct {
+	// Index of the row, starting from 1.
+	Row int
+	Err error
+}
+
+func (err *ImportRowError) Error() string {
+	return fmt.Sprintf("Row %d: %s", err.Row, err.Err)
+}
+
+// ImportBatch contains tables, categories and menu items, which are imported together.
+type ImportBatch struct {
+	Tables     []Table
+	Categories []MenuCategory
+	Menu       []MenuItem
+	// Category names of menu items with the same index. Not empty name is resolved
+	// to imported category or to existing one and overrides CategoryID.
+	MenuCategories []string
+}
+
+// Import inserts all rows of the batch in one transaction.
+// Nothing is inserted, when any row fails.
+func (batch *ImportBatch) Import(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := batch.insert(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (batch *ImportBatch) insert(tx *sqlx.Tx) error {
+	for i := range batch.Tables {
+		table := &batch.Tables[i]
+
+		query := `INSERT INTO tables (places, description, active) VALUES (?, ?, ?)`
+
+		result, err := tx.Exec(query, table.Places, table.Description, table.Active)
+		if err != nil {
+			return &ImportRowError{Row: i + 1, Err: err}
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		table.ID = uint64(id)
+	}
+
+	categories := make(map[string]uint64)
+
+	for i := range batch.Categories {
+		category := &batch.Categories[i]
+
+		result, err := tx.Exec("INSERT INTO categories (name, `order`) VALUES (?, ?)", category.Name, category.Order)
+		if err != nil {
+			return &ImportRowError{Row: i + 1, Err: err}
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		category.ID = uint64(id)
+		categories[category.Name] = category.ID
+	}
+
+	for i := range batch.Menu {
+		menuItem := &batch.Menu[i]
+
+		if i < len(batch.MenuCategories) && batch.MenuCategories[i] != "" {
+			name := batch.MenuCategories[i]
+			id, ok := categories[name]
+
+			if !ok {
+				if err := tx.Get(&id, `SELECT id FROM categories WHERE name = ? LIMIT 1`, name); err != nil {
+					errorMsg := fmt.Sprintf("Category %s does not exist", name)
+					return &ImportRowError{Row: i + 1, Err: ValidationErrors{{Field: "category", Error: errorMsg}}}
+				}
+			}
+
+			menuItem.CategoryID = id
+		}
+
+		query := `INSERT INTO menu (name, description, price, category_id, image_url, active)
+			VALUES (:name, :description, :price, :category_id, :image_url, :active)`
+
+		result, err := tx.NamedExec(query, menuItem)
+		if err != nil {
+			return &ImportRowError{Row: i + 1, Err: err}
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		menuItem.ID = uint64(id)
+
+		if err := menuItem.saveTags(tx); err != nil {
+			return &ImportRowError{Row: i + 1, Err: err}
+		}
+
+		if err := menuItem.savePrice(tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringList is list of strings, stored in DB as comma-separated value.
+type StringList []string
+
+// Scan implements sql.Scanner interface.
+func (list *StringList) Scan(value interface{}) error {
+	var raw string
+
+	switch v := value.(type) {
+	case nil:
+		raw = ""
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("Unsupported type %T for string list", value)
+	}
+
+	*list = make(StringList, 0)
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer interface.
+func (list StringList) Value() (driver.Value, error) {
+	return strings.Join(list, ","), nil
+}
+
+// Contains returns true, when list contains value.
+func (list StringList) Contains(value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
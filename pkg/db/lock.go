@@ -0,0 +1,45 @@
+package db
+
+import (
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Acquire tries to take job lock until specified time.
+// Returns true, when lock is free, expired or already held by the same owner.
+func (lock *JobLock) Acquire(db *sqlx.DB, now time.Time, ttl time.Duration) (bool, error) {
+	// Make sure, that lock row exists, so it could be taken by update below.
+	insertStatement := `INSERT IGNORE INTO job_locks (name, owner, locked_until) VALUES (?, '', ?)`
+
+	if _, err := db.Exec(insertStatement, lock.Name, now); err != nil {
+		return false, err
+	}
+
+	lockedUntil := now.Add(ttl)
+
+	updateStatement := `UPDATE job_locks SET owner = ?, locked_until = ?
+		WHERE name = ? AND (locked_until <= ? OR owner = ?)`
+
+	result, err := db.Exec(updateStatement, lock.Owner, lockedUntil, lock.Name, now, lock.Owner)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	lock.LockedUntil = lockedUntil
+
+	return true, nil
+}
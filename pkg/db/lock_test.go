@@ -0,0 +1,74 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+type AcquireJobLockSuite struct {
+	suite.Suite
+	Lock JobLock
+	Now  time.Time
+	TTL  time.Duration
+	DB   *sqlx.DB
+	Mock sqlmock.Sqlmock
+}
+
+func (suite *AcquireJobLockSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		suite.T().Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	suite.Mock = mock
+	suite.DB = sqlx.NewDb(db, "sqlmock")
+	suite.Lock = JobLock{Name: "reminders", Owner: "replica-1"}
+	suite.Now = time.Date(2019, 11, 25, 20, 0, 0, 0, time.UTC)
+	suite.TTL = time.Minute
+
+	suite.Mock.ExpectExec("^INSERT IGNORE INTO job_locks (.+)").
+		WithArgs(suite.Lock.Name, suite.Now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (suite *AcquireJobLockSuite) AfterTest(suiteName, testName string) {
+	// Make sure that all expectations were met.
+	if err := suite.Mock.ExpectationsWereMet(); err != nil {
+		suite.T().Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	suite.DB.Close()
+}
+
+func (suite *AcquireJobLockSuite) TestAcquireFreeLock() {
+	suite.Mock.ExpectExec("^UPDATE job_locks SET (.+)").
+		WithArgs(suite.Lock.Owner, suite.Now.Add(suite.TTL), suite.Lock.Name, suite.Now, suite.Lock.Owner).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	acquired, err := suite.Lock.Acquire(suite.DB, suite.Now, suite.TTL)
+
+	suite.Nil(err)
+	suite.True(acquired)
+	suite.Equal(suite.Now.Add(suite.TTL), suite.Lock.LockedUntil)
+}
+
+func (suite *AcquireJobLockSuite) TestAcquireTakenLock() {
+	suite.Mock.ExpectExec("^UPDATE job_locks SET (.+)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	acquired, err := suite.Lock.Acquire(suite.DB, suite.Now, suite.TTL)
+
+	suite.Nil(err)
+	suite.False(acquired)
+}
+
+func TestAcquireJobLockSuite(t *testing.T) {
+	suite.Run(t, new(AcquireJobLockSuite))
+}
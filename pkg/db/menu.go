@@ -1,10 +1,39 @@
 package db
 
+import (
+	"strings"
+)
+
 import (
 	"github.com/jmoiron/sqlx"
 )
 
-// GetAll returns list of all menu items.
+// Validate validates and normalizes menu item fields.
+func (menuItem *MenuItem) Validate() error {
+	errs := ValidationErrors{}
+
+	menuItem.Name = strings.TrimSpace(menuItem.Name)
+	errs.check(len(menuItem.Name) != 0, "name", "Name should not be empty")
+	errs.check(menuItem.Price > 0, "price", "Price should be more than 0")
+	validateAvailability(&errs, menuItem.Availability)
+
+	return errs.err()
+}
+
+// Loads option groups, tags and availability for every menu item in the list.
+func loadMenuDetails(db *sqlx.DB, menuItems []MenuItem) error {
+	if err := loadOptionGroups(db, menuItems); err != nil {
+		return err
+	}
+
+	if err := loadMenuTags(db, menuItems); err != nil {
+		return err
+	}
+
+	return loadMenuAvailability(db, menuItems)
+}
+
+// GetAll returns list of all menu items with their option groups and tags.
 func (MenuItem) GetAll(db *sqlx.DB) (*[]MenuItem, error) {
 	menuItems := make([]MenuItem, 0)
 
@@ -12,6 +41,10 @@ func (MenuItem) GetAll(db *sqlx.DB) (*[]MenuItem, error) {
 		return nil, err
 	}
 
+	if err := loadMenuDetails(db, menuItems); err != nil {
+		return nil, err
+	}
+
 	return &menuItems, nil
 }
 
@@ -23,18 +56,26 @@ func (MenuItem) GetByCategory(db *sqlx.DB, categoryID uint64) (*[]MenuItem, erro
 		return nil, err
 	}
 
+	if err := loadMenuDetails(db, menuItems); err != nil {
+		return nil, err
+	}
+
 	return &menuItems, nil
 }
 
-// Find returns MenuItem object with specified ID.
+// Find returns MenuItem object with specified ID, its option groups and tags.
 func (MenuItem) Find(db *sqlx.DB, id uint64) (*MenuItem, error) {
-	menuItem := MenuItem{}
+	menuItems := make([]MenuItem, 1)
 
-	if err := db.Get(&menuItem, "SELECT * FROM menu WHERE id = ?", id); err != nil {
+	if err := db.Get(&menuItems[0], "SELECT * FROM menu WHERE id = ?", id); err != nil {
 		return nil, err
 	}
 
-	return &menuItem, nil
+	if err := loadMenuDetails(db, menuItems); err != nil {
+		return nil, err
+	}
+
+	return &menuItems[0], nil
 }
 
 // Destroy menu item with specified ID.
@@ -51,47 +92,118 @@ func (MenuItem) Destroy(db *sqlx.DB, id uint64) error {
 	return nil
 }
 
-// Update menu item object in DB.
+// Update menu item object, its tags and availability in DB.
+// Changed price is recorded in price history.
 func (menuItem *MenuItem) Update(db *sqlx.DB) error {
+	existingItem, err := MenuItem.Find(MenuItem{}, db, menuItem.ID)
+	if err != nil {
+		return err
+	}
 
-	if _, err := MenuItem.Find(MenuItem{}, db, menuItem.ID); err != nil {
+	tx, err := db.Beginx()
+	if err != nil {
 		return err
 	}
 
+	// Thumbnail is assigned first, so it is compared with previous image URL.
 	query := `UPDATE menu SET
+		thumbnail_url=IF(image_url = :image_url, thumbnail_url, ''),
 		name=:name, description=:description, price=:price, category_id=:category_id, image_url=:image_url, active=:active
 		WHERE id=:id`
-	_, err := db.NamedExec(query, menuItem)
 
+	if _, err := tx.NamedExec(query, menuItem); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := menuItem.saveTags(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveAvailability(tx, "menu_item_id", menuItem.ID, menuItem.Availability); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if menuItem.Price != existingItem.Price {
+		if err := menuItem.savePrice(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	updatedItem, err := MenuItem.Find(MenuItem{}, db, menuItem.ID)
 	if err != nil {
 		return err
 	}
+	*menuItem = *updatedItem
 
 	return nil
 }
 
-// Insert adds new menu item.
+// SetImage sets URLs of uploaded image and its thumbnail.
+func (menuItem *MenuItem) SetImage(db *sqlx.DB, imageURL, thumbnailURL string) error {
+	sql := `UPDATE menu SET image_url = ?, thumbnail_url = ? WHERE id = ?`
+
+	if _, err := db.Exec(sql, imageURL, thumbnailURL, menuItem.ID); err != nil {
+		return err
+	}
+
+	menuItem.ImageURL = imageURL
+	menuItem.ThumbnailURL = thumbnailURL
+
+	return nil
+}
+
+// Insert adds new menu item with its tags, availability and initial price in price history.
 func (menuItem *MenuItem) Insert(db *sqlx.DB) error {
-	sqlStatement := `INSERT INTO menu (name, description, price, category_id, image_url, active) VALUES (?, ?, ?, ?, ?, ?)`
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
 
-	result, err := db.Exec(sqlStatement,
-		menuItem.Name,
-		menuItem.Description,
-		menuItem.Price,
-		menuItem.CategoryID,
-		menuItem.ImageURL,
-		menuItem.Active)
+	query := `INSERT INTO menu (name, description, price, category_id, image_url, active)
+		VALUES (:name, :description, :price, :category_id, :image_url, :active)`
 
+	result, err := tx.NamedExec(query, menuItem)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
-	id, err := result.LastInsertId()
 
+	id, err := result.LastInsertId()
 	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	menuItem.ID = uint64(id)
+
+	if err := menuItem.saveTags(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveAvailability(tx, "menu_item_id", menuItem.ID, menuItem.Availability); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := menuItem.savePrice(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
-	createdItem, err := MenuItem.Find(MenuItem{}, db, uint64(id))
+	createdItem, err := MenuItem.Find(MenuItem{}, db, menuItem.ID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Validate validates and normalizes option group fields.
+// Single choice group allows to choose at most one option.
+func (group *OptionGroup) Validate() error {
+	errs := ValidationErrors{}
+
+	errs.check(group.MenuItemID != 0, "menu_item_id", "Menu item should be specified")
+
+	group.Name = strings.TrimSpace(group.Name)
+	errs.check(len(group.Name) != 0, "name", "Name should not be empty")
+
+	if group.Type == "" {
+		group.Type = OptionGroupSingle
+	}
+
+	switch group.Type {
+	case OptionGroupSingle:
+		group.MaxChoices = 1
+		errs.check(group.MinChoices == 0 || group.MinChoices == 1, "min_choices",
+			"Minimal number of choices should be 0 or 1 for single choice group")
+	case OptionGroupMulti:
+		errs.check(group.MinChoices >= 0, "min_choices", "Minimal number of choices should not be negative")
+		errs.check(group.MaxChoices >= 0, "max_choices", "Maximal number of choices should not be negative")
+		errs.check(group.MaxChoices == 0 || group.MinChoices <= group.MaxChoices, "max_choices",
+			"Maximal number of choices should not be less than minimal")
+	default:
+		errs.check(false, "type", "Type is invalid, should be single or multi")
+	}
+
+	return errs.err()
+}
+
+// Validate validates and normalizes option fields.
+func (option *MenuOption) Validate() error {
+	errs := ValidationErrors{}
+
+	errs.check(option.GroupID != 0, "group_id", "Option group should be specified")
+
+	option.Name = strings.TrimSpace(option.Name)
+	errs.check(len(option.Name) != 0, "name", "Name should not be empty")
+
+	return errs.err()
+}
+
+// Loads option groups with their options for every menu item in the list.
+func loadOptionGroups(db *sqlx.DB, menuItems []MenuItem) error {
+	if len(menuItems) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(menuItems))
+	for i, menuItem := range menuItems {
+		ids[i] = menuItem.ID
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM option_groups WHERE menu_item_id IN (?) ORDER BY `order`, id", ids)
+	if err != nil {
+		return err
+	}
+
+	groups := make([]OptionGroup, 0)
+	if err := db.Select(&groups, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	if err := loadOptions(db, groups); err != nil {
+		return err
+	}
+
+	byItem := make(map[uint64][]OptionGroup)
+	for _, group := range groups {
+		byItem[group.MenuItemID] = append(byItem[group.MenuItemID], group)
+	}
+
+	for i := range menuItems {
+		menuItems[i].OptionGroups = byItem[menuItems[i].ID]
+
+		if menuItems[i].OptionGroups == nil {
+			menuItems[i].OptionGroups = make([]OptionGroup, 0)
+		}
+	}
+
+	return nil
+}
+
+// Loads options for every group in the list.
+func loadOptions(db *sqlx.DB, groups []OptionGroup) error {
+	for i := range groups {
+		groups[i].Options = make([]MenuOption, 0)
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM menu_options WHERE group_id IN (?) ORDER BY `order`, id", ids)
+	if err != nil {
+		return err
+	}
+
+	options := make([]MenuOption, 0)
+	if err := db.Select(&options, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	index := make(map[uint64]int)
+	for i, group := range groups {
+		index[group.ID] = i
+	}
+
+	for _, option := range options {
+		group := &groups[index[option.GroupID]]
+		group.Options = append(group.Options, option)
+	}
+
+	return nil
+}
+
+// GetByMenuItem returns option groups of menu item with their options.
+func (OptionGroup) GetByMenuItem(db *sqlx.DB, menuItemID uint64) (*[]OptionGroup, error) {
+	groups := make([]OptionGroup, 0)
+
+	if err := db.Select(&groups, "SELECT * FROM option_groups WHERE menu_item_id = ? ORDER BY `order`, id", menuItemID); err != nil {
+		return nil, err
+	}
+
+	if err := loadOptions(db, groups); err != nil {
+		return nil, err
+	}
+
+	return &groups, nil
+}
+
+// Find returns OptionGroup object with specified ID and its options.
+func (OptionGroup) Find(db *sqlx.DB, id uint64) (*OptionGroup, error) {
+	groups := make([]OptionGroup, 1)
+
+	if err := db.Get(&groups[0], `SELECT * FROM option_groups WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	if err := loadOptions(db, groups); err != nil {
+		return nil, err
+	}
+
+	return &groups[0], nil
+}
+
+// Destroy option group with specified ID, its options are deleted too.
+func (OptionGroup) Destroy(db *sqlx.DB, id uint64) error {
+	if _, err := OptionGroup.Find(OptionGroup{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM option_groups WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update option group object in DB.
+func (group *OptionGroup) Update(db *sqlx.DB) error {
+	if _, err := OptionGroup.Find(OptionGroup{}, db, group.ID); err != nil {
+		return err
+	}
+
+	query := "UPDATE option_groups SET " +
+		"menu_item_id=:menu_item_id, name=:name, type=:type, min_choices=:min_choices, max_choices=:max_choices, `order`=:order " +
+		"WHERE id=:id"
+
+	if _, err := db.NamedExec(query, group); err != nil {
+		return err
+	}
+
+	updatedGroup, err := OptionGroup.Find(OptionGroup{}, db, group.ID)
+	if err != nil {
+		return err
+	}
+	*group = *updatedGroup
+
+	return nil
+}
+
+// Insert adds new option group.
+func (group *OptionGroup) Insert(db *sqlx.DB) error {
+	query := "INSERT INTO option_groups (menu_item_id, name, type, min_choices, max_choices, `order`) " +
+		"VALUES (:menu_item_id, :name, :type, :min_choices, :max_choices, :order)"
+
+	result, err := db.NamedExec(query, group)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	createdGroup, err := OptionGroup.Find(OptionGroup{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*group = *createdGroup
+
+	return nil
+}
+
+// Find returns MenuOption object with specified ID.
+func (MenuOption) Find(db *sqlx.DB, id uint64) (*MenuOption, error) {
+	option := MenuOption{}
+
+	if err := db.Get(&option, `SELECT * FROM menu_options WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return &option, nil
+}
+
+// Destroy option with specified ID.
+func (MenuOption) Destroy(db *sqlx.DB, id uint64) error {
+	if _, err := MenuOption.Find(MenuOption{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM menu_options WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update option object in DB.
+func (option *MenuOption) Update(db *sqlx.DB) error {
+	if _, err := MenuOption.Find(MenuOption{}, db, option.ID); err != nil {
+		return err
+	}
+
+	query := "UPDATE menu_options SET " +
+		"group_id=:group_id, name=:name, price_delta=:price_delta, `order`=:order, active=:active " +
+		"WHERE id=:id"
+
+	if _, err := db.NamedExec(query, option); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Insert adds new option.
+func (option *MenuOption) Insert(db *sqlx.DB) error {
+	query := "INSERT INTO menu_options (group_id, name, price_delta, `order`, active) " +
+		"VALUES (:group_id, :name, :price_delta, :order, :active)"
+
+	result, err := db.NamedExec(query, option)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	createdOption, err := MenuOption.Find(MenuOption{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*option = *createdOption
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Validate validates scheduled price change.
+func (menuPrice *MenuPrice) Validate() error {
+	errs := ValidationErrors{}
+
+	errs.check(menuPrice.Price > 0, "price", "Price should be more than 0")
+	errs.check(menuPrice.EffectiveFrom.After(time.Now()), "effective_from", "Effective time should be in the future")
+
+	return errs.err()
+}
+
+// Records price of menu item, which is effective from now.
+func (menuItem *MenuItem) savePrice(tx *sqlx.Tx) error {
+	query := `INSERT INTO menu_prices (menu_item_id, price, effective_from, applied) VALUES (?, ?, ?, TRUE)`
+
+	_, err := tx.Exec(query, menuItem.ID, menuItem.Price, time.Now().UTC())
+
+	return err
+}
+
+// GetByMenuItem returns price history of menu item, including scheduled changes, ordered by effective time.
+func (MenuPrice) GetByMenuItem(db *sqlx.DB, menuItemID uint64) (*[]MenuPrice, error) {
+	prices := make([]MenuPrice, 0)
+
+	query := `SELECT * FROM menu_prices WHERE menu_item_id = ? ORDER BY effective_from, id`
+	if err := db.Select(&prices, query, menuItemID); err != nil {
+		return nil, err
+	}
+
+	return &prices, nil
+}
+
+// Find returns MenuPrice object with specified ID.
+func (MenuPrice) Find(db *sqlx.DB, id uint64) (*MenuPrice, error) {
+	menuPrice := MenuPrice{}
+
+	if err := db.Get(&menuPrice, `SELECT * FROM menu_prices WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return &menuPrice, nil
+}
+
+// Insert schedules price change of menu item.
+func (menuPrice *MenuPrice) Insert(db *sqlx.DB) error {
+	query := `INSERT INTO menu_prices (menu_item_id, price, effective_from, applied) VALUES (?, ?, ?, FALSE)`
+
+	result, err := db.Exec(query, menuPrice.MenuItemID, menuPrice.Price, menuPrice.EffectiveFrom.UTC())
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	createdPrice, err := MenuPrice.Find(MenuPrice{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*menuPrice = *createdPrice
+
+	return nil
+}
+
+// DestroyScheduled cancels price change of menu item, which is not applied yet.
+func (MenuPrice) DestroyScheduled(db *sqlx.DB, menuItemID, id uint64) error {
+	result, err := db.Exec(`DELETE FROM menu_prices WHERE id = ? AND menu_item_id = ? AND NOT applied`, id, menuItemID)
+
+	if err != nil {
+		return err
+	}
+
+	return checkDeleted(result)
+}
+
+// ApplyDue sets prices of menu items to scheduled prices, which became effective,
+// and returns IDs of changed menu items.
+func (MenuPrice) ApplyDue(db *sqlx.DB) ([]uint64, error) {
+	prices := make([]MenuPrice, 0)
+
+	query := `SELECT * FROM menu_prices WHERE NOT applied AND effective_from <= ? ORDER BY effective_from, id`
+	if err := db.Select(&prices, query, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0)
+
+	for _, menuPrice := range prices {
+		tx, err := db.Beginx()
+		if err != nil {
+			return ids, err
+		}
+
+		if _, err := tx.Exec(`UPDATE menu SET price = ? WHERE id = ?`, menuPrice.Price, menuPrice.MenuItemID); err != nil {
+			tx.Rollback()
+			return ids, err
+		}
+
+		if _, err := tx.Exec(`UPDATE menu_prices SET applied = TRUE WHERE id = ?`, menuPrice.ID); err != nil {
+			tx.Rollback()
+			return ids, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, menuPrice.MenuItemID)
+	}
+
+	return ids, nil
+}
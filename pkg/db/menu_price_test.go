@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestApplyDuePrices(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	effectiveFrom := time.Now().Add(-time.Minute)
+	rows := sqlmock.NewRows([]string{"id", "menu_item_id", "price", "effective_from", "applied", "created_at"}).
+		AddRow(7, 3, "2.250", effectiveFrom, false, effectiveFrom)
+
+	mock.ExpectQuery("^SELECT (.+) FROM menu_prices WHERE NOT applied").WillReturnRows(rows)
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE menu SET price").
+		WithArgs(money.Money(2250), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^UPDATE menu_prices SET applied").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ids, err := MenuPrice.ApplyDue(MenuPrice{}, DB)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,295 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/tools"
+)
+
+// Validate validates and normalizes private event.
+func (event *PrivateEvent) Validate() error {
+	event.Name = strings.TrimSpace(event.Name)
+	if event.Name == "" {
+		return errors.New("Name of the event is invalid")
+	}
+
+	event.ContactName = strings.TrimSpace(event.ContactName)
+	if event.ContactName == "" {
+		return errors.New("Contact name is invalid")
+	}
+
+	if event.ContactEmail == "" && event.ContactPhone == "" {
+		return errors.New("Contact email or phone should be specified")
+	}
+
+	if event.ContactEmail != "" {
+		event.ContactEmail = NormalizeEmail(event.ContactEmail)
+		if !tools.ValidateEmail(event.ContactEmail) {
+			return errors.New("Contact email is invalid")
+		}
+	}
+
+	if event.ContactPhone != "" {
+		phone, err := NormalizePhone(event.ContactPhone)
+		if err != nil {
+			return err
+		}
+		event.ContactPhone = phone
+	}
+
+	if !event.EndsAt.After(event.StartsAt) {
+		return errors.New("Finish time of the event should be after start time")
+	}
+
+	if event.AllTables {
+		event.TableIDs = make([]uint64, 0)
+	} else if len(event.TableIDs) == 0 {
+		return errors.New("Tables should be specified, when not whole venue is blocked")
+	}
+
+	return nil
+}
+
+// BlocksTable checks, whether event blocks table with specified ID.
+func (event *PrivateEvent) BlocksTable(tableID uint64) bool {
+	if event.AllTables {
+		return true
+	}
+
+	for _, id := range event.TableIDs {
+		if id == tableID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Loads IDs of blocked tables.
+func (event *PrivateEvent) loadTables(queryer sqlx.Queryer) error {
+	event.TableIDs = make([]uint64, 0)
+
+	return sqlx.Select(queryer, &event.TableIDs, `SELECT table_id FROM private_event_tables WHERE event_id = ? ORDER BY table_id`, event.ID)
+}
+
+// Loads blocked tables for every event in the list.
+func loadEventTables(queryer sqlx.Queryer, events []PrivateEvent) error {
+	for i := range events {
+		if err := events[i].loadTables(queryer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetAll returns list of all private events, ordered by start time.
+func (PrivateEvent) GetAll(db *sqlx.DB) (*[]PrivateEvent, error) {
+	events := make([]PrivateEvent, 0)
+
+	if err := db.Select(&events, `SELECT * FROM private_events ORDER BY starts_at`); err != nil {
+		return nil, err
+	}
+
+	if err := loadEventTables(db, events); err != nil {
+		return nil, err
+	}
+
+	return &events, nil
+}
+
+// GetOverlapping returns private events, which overlap with specified time range.
+func (PrivateEvent) GetOverlapping(queryer sqlx.Queryer, from, to time.Time) (*[]PrivateEvent, error) {
+	events := make([]PrivateEvent, 0)
+
+	sql := `SELECT * FROM private_events WHERE starts_at < ? AND ends_at > ? ORDER BY starts_at`
+
+	if err := sqlx.Select(queryer, &events, sql, to, from); err != nil {
+		return nil, err
+	}
+
+	if err := loadEventTables(queryer, events); err != nil {
+		return nil, err
+	}
+
+	return &events, nil
+}
+
+// FindBlocking returns private event, which blocks table in specified time range.
+func (PrivateEvent) FindBlocking(queryer sqlx.Queryer, tableID uint64, from, to time.Time) (*PrivateEvent, error) {
+	events, err := PrivateEvent.GetOverlapping(PrivateEvent{}, queryer, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range *events {
+		if event.BlocksTable(tableID) {
+			return &event, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetReservations returns active reservations, which hold tables, blocked by event.
+func (event *PrivateEvent) GetReservations(db *sqlx.DB) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	// Duration is stored in nanoseconds.
+	query := `SELECT * FROM reservations
+		WHERE time < ? AND time + INTERVAL (duration DIV 1000) MICROSECOND > ? AND ` + activeReservationCondition
+	args := []interface{}{event.EndsAt, event.StartsAt, int64(VerificationTTL.Seconds())}
+
+	if !event.AllTables {
+		if len(event.TableIDs) == 0 {
+			return &reservations, nil
+		}
+
+		query += ` AND table_id IN (?)`
+		args = append(args, event.TableIDs)
+	}
+
+	query, args, err := sqlx.In(query+` ORDER BY time`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Select(&reservations, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// Find returns PrivateEvent object with specified ID.
+func (PrivateEvent) Find(db *sqlx.DB, id uint64) (*PrivateEvent, error) {
+	event := PrivateEvent{}
+
+	if err := db.Get(&event, `SELECT * FROM private_events WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	if err := event.loadTables(db); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Destroy private event with specified ID.
+func (PrivateEvent) Destroy(db *sqlx.DB, id uint64) error {
+	if _, err := PrivateEvent.Find(PrivateEvent{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM private_events WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Replaces blocked tables of the event.
+func (event *PrivateEvent) saveTables(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM private_event_tables WHERE event_id = ?`, event.ID); err != nil {
+		return err
+	}
+
+	for _, tableID := range event.TableIDs {
+		sql := `INSERT INTO private_event_tables (event_id, table_id) VALUES (?, ?)`
+
+		if _, err := tx.Exec(sql, event.ID, tableID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Insert adds new private event with its tables.
+func (event *PrivateEvent) Insert(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO private_events
+		(name, contact_name, contact_email, contact_phone, starts_at, ends_at, all_tables, notes)
+		VALUES (:name, :contact_name, :contact_email, :contact_phone, :starts_at, :ends_at, :all_tables, :notes)`
+
+	result, err := tx.NamedExec(query, event)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	event.ID = uint64(id)
+
+	if err := event.saveTables(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	createdEvent, err := PrivateEvent.Find(PrivateEvent{}, db, event.ID)
+	if err != nil {
+		return err
+	}
+	*event = *createdEvent
+
+	return nil
+}
+
+// Update private event object and its tables in DB.
+func (event *PrivateEvent) Update(db *sqlx.DB) error {
+	if _, err := PrivateEvent.Find(PrivateEvent{}, db, event.ID); err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE private_events SET
+		name=:name, contact_name=:contact_name, contact_email=:contact_email, contact_phone=:contact_phone,
+		starts_at=:starts_at, ends_at=:ends_at, all_tables=:all_tables, notes=:notes
+		WHERE id = :id`
+
+	if _, err := tx.NamedExec(query, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := event.saveTables(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	updatedEvent, err := PrivateEvent.Find(PrivateEvent{}, db, event.ID)
+	if err != nil {
+		return err
+	}
+	*event = *updatedEvent
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var privateEventColumns = []string{"id", "name", "starts_at", "ends_at", "all_tables"}
+
+var reservationColumns = []string{"id", "table_id", "state", "time", "duration"}
+
+func TestPrivateEventBlocksTable(t *testing.T) {
+	event := PrivateEvent{TableIDs: []uint64{2, 3}}
+
+	assert.True(t, event.BlocksTable(2))
+	assert.False(t, event.BlocksTable(4))
+
+	venue := PrivateEvent{AllTables: true}
+
+	assert.True(t, venue.BlocksTable(4))
+}
+
+func TestGetReservationsOfEventTables(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	start := time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC)
+	event := PrivateEvent{StartsAt: start, EndsAt: start.Add(4 * time.Hour), TableIDs: []uint64{2, 3}}
+
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE time < \\? AND time \\+ INTERVAL (.+) > \\? AND (.+) AND table_id IN \\(\\?, \\?\\)").
+		WithArgs(event.EndsAt, event.StartsAt, int64(VerificationTTL.Seconds()), 2, 3).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).AddRow(1, 2, StateApproved, start.Add(-time.Hour), 2*time.Hour))
+
+	reservations, err := event.GetReservations(DB)
+
+	assert.NoError(t, err)
+	assert.Len(t, *reservations, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReservationsOfWholeVenue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	start := time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC)
+	event := PrivateEvent{StartsAt: start, EndsAt: start.Add(4 * time.Hour), AllTables: true}
+
+	// Reservations of every table are returned, without table filter.
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE time < \\? AND time \\+ INTERVAL (.+) > \\?").
+		WithArgs(event.EndsAt, event.StartsAt, int64(VerificationTTL.Seconds())).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).
+			AddRow(1, 2, StateApproved, start, time.Hour).
+			AddRow(2, 7, StateCreated, start.Add(time.Hour), time.Hour))
+
+	reservations, err := event.GetReservations(DB)
+
+	assert.NoError(t, err)
+	assert.Len(t, *reservations, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateTimeOfBlockedTable(t *testing.T) {
+	start := time.Date(2019, 12, 20, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		id        uint64
+		allTables bool
+		tables    []uint64
+		expected  error
+	}{
+		{name: "new reservation at blocked table", tables: []uint64{2}, expected: errPrivateEvent},
+		{name: "updated reservation at blocked table", id: 9, tables: []uint64{2}, expected: errPrivateEvent},
+		{name: "whole venue is blocked", allTables: true, expected: errPrivateEvent},
+		{name: "other tables are blocked", tables: []uint64{3, 4}, expected: nil},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE table_id = \\? AND id <> \\?").
+			WithArgs(2, test.id, int64(VerificationTTL.Seconds())).
+			WillReturnRows(sqlmock.NewRows(reservationColumns))
+
+		reservation := Reservation{ID: test.id, TableID: 2, Time: start.Add(time.Hour), Duration: 2 * time.Hour}
+
+		mock.ExpectQuery("^SELECT (.+) FROM private_events WHERE starts_at < \\? AND ends_at > \\?").
+			WithArgs(reservation.GetStopTime(), reservation.Time).
+			WillReturnRows(sqlmock.NewRows(privateEventColumns).AddRow(1, "Wedding", start, start.Add(4*time.Hour), test.allTables))
+
+		tables := sqlmock.NewRows([]string{"table_id"})
+		for _, id := range test.tables {
+			tables.AddRow(id)
+		}
+
+		mock.ExpectQuery("^SELECT table_id FROM private_event_tables").WithArgs(1).WillReturnRows(tables)
+
+		assert.Equal(t, test.expected, reservation.validateTime(DB), test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported recurrence frequencies.
+const (
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+)
+
+// MaxOccurrences is maximum number of reservations, generated for one series.
+const MaxOccurrences = 104
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Recurrence is parsed subset of iCalendar RRULE:
+// FREQ=WEEKLY or MONTHLY, INTERVAL, BYDAY for weekly and BYMONTHDAY for monthly rules.
+type Recurrence struct {
+	Frequency  string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+}
+
+// ParseRecurrence parses rule like "FREQ=WEEKLY;INTERVAL=1;BYDAY=TH".
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	recurrence := Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.ToUpper(rule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+
+		pair := strings.SplitN(part, "=", 2)
+
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("Invalid rule part %s", part)
+		}
+
+		key, value := pair[0], pair[1]
+
+		switch key {
+		case "FREQ":
+			if value != FrequencyWeekly && value != FrequencyMonthly {
+				return nil, errors.New("Frequency should be WEEKLY or MONTHLY")
+			}
+			recurrence.Frequency = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval <= 0 {
+				return nil, errors.New("Interval should be positive number")
+			}
+			recurrence.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("Invalid day %s", code)
+				}
+				recurrence.ByDay = append(recurrence.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil || day < 1 || day > 31 {
+				return nil, errors.New("Day of month should be between 1 and 31")
+			}
+			recurrence.ByMonthDay = day
+		default:
+			return nil, fmt.Errorf("Unsupported rule part %s", key)
+		}
+	}
+
+	if recurrence.Frequency == "" {
+		return nil, errors.New("Frequency is required")
+	}
+
+	if recurrence.Frequency == FrequencyWeekly && recurrence.ByMonthDay != 0 {
+		return nil, errors.New("BYMONTHDAY is not supported for weekly rules")
+	}
+
+	if recurrence.Frequency == FrequencyMonthly && len(recurrence.ByDay) != 0 {
+		return nil, errors.New("BYDAY is not supported for monthly rules")
+	}
+
+	return &recurrence, nil
+}
+
+// Occurrences returns start times of occurrences from start until specified time inclusive.
+// Time of day is taken from start. Result is limited to MaxOccurrences.
+func (recurrence Recurrence) Occurrences(start, until time.Time) []time.Time {
+	return recurrence.occurrences(start, until, MaxOccurrences)
+}
+
+func (recurrence Recurrence) occurrences(start, until time.Time, limit int) []time.Time {
+	occurrences := make([]time.Time, 0)
+
+	add := func(occurrence time.Time) bool {
+		if occurrence.Before(start) {
+			return true
+		}
+
+		if occurrence.After(until) || len(occurrences) >= limit {
+			return false
+		}
+
+		occurrences = append(occurrences, occurrence)
+
+		return true
+	}
+
+	if recurrence.Frequency == FrequencyMonthly {
+		day := recurrence.ByMonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+
+		for month := 0; ; month += recurrence.Interval {
+			firstDay := time.Date(start.Year(), start.Month()+time.Month(month), 1,
+				start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+
+			if firstDay.After(until) {
+				break
+			}
+
+			occurrence := firstDay.AddDate(0, 0, day-1)
+
+			// Skip months without such day, e.g. 31st of April.
+			if occurrence.Month() != firstDay.Month() {
+				continue
+			}
+
+			if !add(occurrence) {
+				break
+			}
+		}
+
+		return occurrences
+	}
+
+	days := recurrence.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{start.Weekday()}
+	}
+
+	// Weeks start on Sunday, days are iterated in week order to keep occurrences sorted.
+	selected := make([]bool, 7)
+	for _, weekday := range days {
+		selected[weekday] = true
+	}
+
+	weekStart := start.AddDate(0, 0, -int(start.Weekday()))
+
+	for week := 0; ; week += recurrence.Interval {
+		current := weekStart.AddDate(0, 0, week*7)
+
+		if current.After(until) {
+			break
+		}
+
+		for weekday := 0; weekday < 7; weekday++ {
+			if !selected[weekday] {
+				continue
+			}
+
+			if !add(current.AddDate(0, 0, weekday)) {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
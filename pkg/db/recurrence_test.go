@@ -0,0 +1,119 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected *Recurrence
+	}{
+		{"FREQ=WEEKLY", &Recurrence{Frequency: FrequencyWeekly, Interval: 1}},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TH", &Recurrence{
+			Frequency: FrequencyWeekly, Interval: 2, ByDay: []time.Weekday{time.Thursday},
+		}},
+		{"FREQ=MONTHLY;BYMONTHDAY=15", &Recurrence{Frequency: FrequencyMonthly, Interval: 1, ByMonthDay: 15}},
+		{"FREQ=DAILY", nil},
+		{"INTERVAL=1", nil},
+		{"FREQ=WEEKLY;BYDAY=XX", nil},
+		{"FREQ=WEEKLY;COUNT=5", nil},
+		{"FREQ=MONTHLY;BYDAY=MO", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(test.rule)
+
+			if test.expected == nil {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, recurrence)
+		})
+	}
+}
+
+func TestWeeklyOccurrences(t *testing.T) {
+	// 2019-11-28 is Thursday.
+	start := time.Date(2019, 11, 28, 12, 30, 0, 0, time.UTC)
+	until := time.Date(2019, 12, 19, 23, 59, 0, 0, time.UTC)
+
+	recurrence := Recurrence{Frequency: FrequencyWeekly, Interval: 1}
+
+	assert.Equal(t, []time.Time{
+		start,
+		time.Date(2019, 12, 5, 12, 30, 0, 0, time.UTC),
+		time.Date(2019, 12, 12, 12, 30, 0, 0, time.UTC),
+		time.Date(2019, 12, 19, 12, 30, 0, 0, time.UTC),
+	}, recurrence.Occurrences(start, until))
+}
+
+func TestWeeklyOccurrencesByDay(t *testing.T) {
+	start := time.Date(2019, 11, 28, 12, 30, 0, 0, time.UTC)
+	until := time.Date(2019, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	recurrence := Recurrence{
+		Frequency: FrequencyWeekly,
+		Interval:  1,
+		ByDay:     []time.Weekday{time.Thursday, time.Monday},
+	}
+
+	assert.Equal(t, []time.Time{
+		start,
+		time.Date(2019, 12, 2, 12, 30, 0, 0, time.UTC),
+		time.Date(2019, 12, 5, 12, 30, 0, 0, time.UTC),
+		time.Date(2019, 12, 9, 12, 30, 0, 0, time.UTC),
+	}, recurrence.Occurrences(start, until))
+}
+
+func TestMonthlyOccurrencesSkipMissingDays(t *testing.T) {
+	start := time.Date(2020, 1, 31, 19, 0, 0, 0, time.UTC)
+	until := time.Date(2020, 5, 31, 23, 0, 0, 0, time.UTC)
+
+	recurrence := Recurrence{Frequency: FrequencyMonthly, Interval: 1}
+
+	assert.Equal(t, []time.Time{
+		start,
+		time.Date(2020, 3, 31, 19, 0, 0, 0, time.UTC),
+		time.Date(2020, 5, 31, 19, 0, 0, 0, time.UTC),
+	}, recurrence.Occurrences(start, until))
+}
+
+func TestOccurrencesAreLimited(t *testing.T) {
+	start := time.Date(2019, 11, 28, 12, 30, 0, 0, time.UTC)
+	until := start.AddDate(10, 0, 0)
+
+	recurrence := Recurrence{Frequency: FrequencyWeekly, Interval: 1}
+
+	assert.Len(t, recurrence.Occurrences(start, until), MaxOccurrences)
+}
+
+func TestValidateSeriesRejectsTooManyOccurrences(t *testing.T) {
+	start := time.Date(2019, 11, 28, 12, 30, 0, 0, time.UTC)
+
+	series := ReservationSeries{
+		Guests:   2,
+		Duration: time.Hour,
+		Email:    "guest@example.com",
+		Phone:    "+97336123456",
+		FullName: "Guest",
+		Rule:     "FREQ=WEEKLY",
+		StartsAt: start,
+		Until:    start.AddDate(10, 0, 0),
+	}
+
+	assert.EqualError(t, series.Validate(), "Series should not have more than 104 occurrences, end date should be earlier")
+
+	series.Until = start.AddDate(1, 0, 0)
+
+	assert.NoError(t, series.Validate())
+	assert.Equal(t, OccasionNone, series.Occasion)
+}
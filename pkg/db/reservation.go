@@ -2,14 +2,15 @@ package db
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/palestine-nights/backend/pkg/tools"
-	"github.com/ttacon/libphonenumber"
 )
 
 // GetStopTime calculates finish time of reservations.
@@ -22,61 +23,256 @@ func isOverlap(start1, finish1, start2, finish2 time.Time) bool {
 		start1.Before(finish2) && start2.Before(finish1)
 }
 
-// Validates time to be not taken to create new table reservation record.
-func (reservation *Reservation) validateTime(db *sqlx.DB) error {
-	// TODO: List only 'approved' records.
+// Errors of reservation time, which is not available.
+var (
+	errTimeTaken    = errors.New("This time was already taken")
+	errPrivateEvent = errors.New("This time is reserved for private event")
+)
+
+// States, which reservation could be moved to from its current state.
+// Cancelled, completed and no-show reservations are final.
+var stateTransitions = map[State][]State{
+	StateCreated:  {StateApproved, StateCancelled},
+	StateApproved: {StateSeated, StateCancelled, StateNoShow},
+	StateSeated:   {StateCompleted},
+}
+
+// StateTransitionError is returned, when reservation could not be moved to requested state.
+type StateTransitionError struct {
+	From State
+	To   State
+	// Reservation is not verified, so it could not be approved.
+	Unverified bool
+}
+
+func (err *StateTransitionError) Error() string {
+	if err.Unverified {
+		return fmt.Sprintf("Reservation could not be moved to state %s before it is verified", err.To)
+	}
+
+	return fmt.Sprintf("Reservation could not be moved from state %s to state %s", err.From, err.To)
+}
+
+// CanChangeState returns error, when reservation could not be moved to the state.
+// Created reservation is approved only after its contact is verified.
+func (reservation *Reservation) CanChangeState(state State) error {
+	allowed := false
+
+	for _, tmp := range stateTransitions[reservation.State] {
+		if tmp == state {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return &StateTransitionError{From: reservation.State, To: state}
+	}
+
+	if reservation.State == StateCreated && state == StateApproved && reservation.VerifiedAt == nil {
+		return &StateTransitionError{From: reservation.State, To: state, Unverified: true}
+	}
+
+	return nil
+}
+
+// Condition for reservations, which hold the table: not cancelled or finished,
+// and either verified, approved or still waiting for verification.
+const activeReservationCondition = `state IN ('created', 'approved', 'seated')
+	AND (state <> 'created' OR verified_at IS NOT NULL OR created_at > NOW() - INTERVAL ? SECOND)`
+
+// Validates time to be not taken by other reservations or private events
+// to create new table reservation record.
+func (reservation *Reservation) validateTime(queryer sqlx.Queryer) error {
 	reservations := make([]Reservation, 0)
 
-	sql := `SELECT * FROM reservations WHERE table_id = ?`
+	// Reservation itself is excluded, when existing reservation is updated.
+	sql := `SELECT * FROM reservations WHERE table_id = ? AND id <> ? AND ` + activeReservationCondition
 
-	if err := db.Select(&reservations, sql, reservation.TableID); err != nil {
+	if err := sqlx.Select(queryer, &reservations, sql, reservation.TableID, reservation.ID, int64(VerificationTTL.Seconds())); err != nil {
 		return err
 	}
 
 	for _, tmp := range reservations {
 		if isOverlap(reservation.Time, reservation.GetStopTime(), tmp.Time, tmp.GetStopTime()) {
-			return errors.New("This time was already taken")
+			return errTimeTaken
 		}
 	}
 
+	event, err := PrivateEvent.FindBlocking(PrivateEvent{}, queryer, reservation.TableID, reservation.Time, reservation.GetStopTime())
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		return errPrivateEvent
+	}
+
 	return nil
 }
 
-// Validate validates all conditions to create new table reservation record.
-func (reservation *Reservation) Validate(db *sqlx.DB) error {
-	// Validates email.
+// ValidateUpdate validates fields of existing reservation
+// and that table is not taken by other reservations at its time.
+// Error is returned only when DB query fails.
+func (reservation *Reservation) ValidateUpdate(db *sqlx.DB) ([]FieldError, error) {
+	timeErrors, err := reservation.timeErrors(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(reservation.fieldErrors(), timeErrors...), nil
+}
+
+// Returns error of time field, when table is not available at reservation time.
+func (reservation *Reservation) timeErrors(db *sqlx.DB) ([]FieldError, error) {
+	err := reservation.validateTime(db)
+
+	if err == errTimeTaken || err == errPrivateEvent {
+		return []FieldError{{Field: "time", Error: err.Error()}}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return make([]FieldError, 0), nil
+}
+
+// CheckTime returns validation error of time field, when table is taken
+// by other reservations or private event at reservation time.
+func (reservation *Reservation) CheckTime(db *sqlx.DB) error {
+	timeErrors, err := reservation.timeErrors(db)
+
+	if err != nil {
+		return err
+	}
+
+	if len(timeErrors) != 0 {
+		return ValidationErrors(timeErrors)
+	}
+
+	return nil
+}
+
+// Validate validates and normalizes fields, which client could set
+// both on creation and update of the reservation.
+func (reservation *Reservation) Validate() error {
+	return ValidationErrors(reservation.fieldErrors()).err()
+}
+
+// ValidateFields validates all conditions to create new table reservation record
+// and returns errors of every failing field. Error is returned only when DB query fails.
+func (reservation *Reservation) ValidateFields(db *sqlx.DB) ([]FieldError, error) {
+	fieldErrors := make([]FieldError, 0)
+
+	// Validates and normalizes email.
+	reservation.Email = NormalizeEmail(reservation.Email)
 	if !tools.ValidateEmail(reservation.Email) {
-		return errors.New("Email is invalid")
+		fieldErrors = append(fieldErrors, FieldError{Field: "email", Error: "Email is invalid"})
 	}
 
 	// Validates and formats phone number.
-	phoneNumber, err := libphonenumber.Parse(reservation.Phone, "BH")
-	if err != nil || !libphonenumber.IsValidNumber(phoneNumber) {
-		return errors.New("Phone is invalid")
+	phone, err := NormalizePhone(reservation.Phone)
+	if err != nil {
+		fieldErrors = append(fieldErrors, FieldError{Field: "phone", Error: err.Error()})
+	} else {
+		reservation.Phone = phone
 	}
-	// Formats phone number to E164 format.
-	reservation.Phone = libphonenumber.Format(phoneNumber, libphonenumber.E164)
 
-	// TODO: Use this validation, when SMS or email verification will be available to protect system.
-	// reservations := make([]Reservation, 0)
-	// sql := `SELECT * FROM reservations WHERE (created_at >= NOW() - INTERVAL 1 DAY) AND (email = ? OR phone = ?);`
-	// if err := db.Select(&reservations, sql, reservation.Email, reservation.Phone); err != nil {
-	// 	return err
-	// }
-	// if len(reservations) != 0 {
-	// 	return errors.New("Email or phone was already used for last 24 hours")
-	// }
+	// Allow one active reservation per contact for last 24 hours.
+	// Expired unverified reservations are not counted, so client could try again.
+	if len(fieldErrors) == 0 {
+		reservations := make([]Reservation, 0)
+		sql := `SELECT * FROM reservations WHERE (created_at >= NOW() - INTERVAL 1 DAY) AND (email = ? OR phone = ?) AND ` +
+			activeReservationCondition
+		if err := db.Select(&reservations, sql, reservation.Email, reservation.Phone, int64(VerificationTTL.Seconds())); err != nil {
+			return nil, err
+		}
+		if len(reservations) != 0 {
+			fieldErrors = append(fieldErrors, FieldError{Field: "contact", Error: "Email or phone was already used for last 24 hours"})
+		}
+	}
 
-	if err := reservation.validateTime(db); err != nil {
-		return err
+	timeErrors, err := reservation.timeErrors(db)
+	if err != nil {
+		return nil, err
 	}
+	fieldErrors = append(fieldErrors, timeErrors...)
 
 	reservation.FullName = strings.TrimSpace(reservation.FullName)
 	if len(reservation.FullName) == 0 {
-		return errors.New("Full Name is invalid")
+		fieldErrors = append(fieldErrors, FieldError{Field: "full_name", Error: "Full Name is invalid"})
 	}
 
-	return nil
+	return append(fieldErrors, reservation.fieldErrors()...), nil
+}
+
+// Returns errors of every invalid field, which does not depend on other records.
+func (reservation *Reservation) fieldErrors() ValidationErrors {
+	errs := ValidationErrors{}
+
+	errs.check(reservation.Guests > 0, "guests", "Invalid number of guests, should be greater that 0")
+	errs.check(reservation.Duration >= MinReservationDuration && reservation.Duration <= MaxReservationDuration,
+		"duration", fmt.Sprintf("Invalid duration time, should be between %s and %s", MinReservationDuration, MaxReservationDuration))
+	errs.check(reservation.Time.After(time.Now()), "time", "Invalid reservation time")
+
+	if reservation.Occasion == "" {
+		reservation.Occasion = OccasionNone
+	}
+	errs.check(reservation.Occasion.IsValid(), "occasion", "Occasion is invalid")
+
+	reservation.AccessibilityNeeds = strings.TrimSpace(reservation.AccessibilityNeeds)
+	errs.check(utf8.RuneCountInString(reservation.AccessibilityNeeds) <= MaxAccessibilityNeedsLength, "accessibility_needs",
+		fmt.Sprintf("Accessibility needs should not be longer than %d characters", MaxAccessibilityNeedsLength))
+
+	reservation.Notes = strings.TrimSpace(reservation.Notes)
+	errs.check(utf8.RuneCountInString(reservation.Notes) <= MaxNotesLength, "notes",
+		fmt.Sprintf("Notes should not be longer than %d characters", MaxNotesLength))
+
+	errs.check(reservation.Children >= 0 && reservation.Children <= reservation.Guests, "children",
+		"Invalid number of children, should not be greater than number of guests")
+	errs.check(reservation.HighChairs >= 0 && reservation.HighChairs <= reservation.Children, "high_chairs",
+		"Invalid number of high chairs, should not be greater than number of children")
+
+	return errs
+}
+
+// GetFiltered returns reservations, matching filter, ordered by time.
+func (Reservation) GetFiltered(db *sqlx.DB, filter ReservationFilter) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	where, args := filter.where()
+
+	if err := db.Select(&reservations, `SELECT * FROM reservations`+where+` ORDER BY time`, args...); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// EachFiltered calls function for every reservation, matching filter, ordered by time.
+// Rows are read one by one from DB cursor, so they are not loaded in memory at once.
+func (Reservation) EachFiltered(db *sqlx.DB, filter ReservationFilter, fn func(*Reservation) error) error {
+	where, args := filter.where()
+
+	rows, err := db.Queryx(`SELECT * FROM reservations`+where+` ORDER BY time`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		reservation := Reservation{}
+
+		if err := rows.StructScan(&reservation); err != nil {
+			return err
+		}
+
+		if err := fn(&reservation); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 // GetAll returns list of all reservations.
@@ -101,6 +297,61 @@ func (Reservation) GetUpcoming(db *sqlx.DB) (*[]Reservation, error) {
 	return &reservations, nil
 }
 
+// GetForReminder returns approved reservations, which start earlier than in specified period
+// and have not been reminded yet.
+func (Reservation) GetForReminder(db *sqlx.DB, before time.Duration) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	sql := `SELECT * FROM reservations
+		WHERE state = ? AND reminder_sent_at IS NULL AND time >= NOW() AND time <= NOW() + INTERVAL ? SECOND`
+
+	if err := db.Select(&reservations, sql, StateApproved, int64(before.Seconds())); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// GetStale returns reservations in "created" state, which were not approved in specified period.
+func (Reservation) GetStale(db *sqlx.DB, window time.Duration) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	sql := `SELECT * FROM reservations WHERE state = ? AND created_at <= NOW() - INTERVAL ? SECOND`
+
+	if err := db.Select(&reservations, sql, StateCreated, int64(window.Seconds())); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// GetFinished returns reservations in specified state, which stop time has already passed.
+func (Reservation) GetFinished(db *sqlx.DB, state State) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	// Duration is stored in nanoseconds.
+	sql := `SELECT * FROM reservations WHERE state = ? AND time + INTERVAL (duration DIV 1000) MICROSECOND <= NOW()`
+
+	if err := db.Select(&reservations, sql, state); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// MarkReminded saves time, when reminder was sent.
+func (reservation *Reservation) MarkReminded(db *sqlx.DB) error {
+	now := time.Now()
+
+	if _, err := db.Exec(`UPDATE reservations SET reminder_sent_at = ? WHERE id = ?`, now, reservation.ID); err != nil {
+		return err
+	}
+
+	reservation.ReminderSentAt = &now
+
+	return nil
+}
+
 // Find returns Reservation's object with specified ID.
 func (Reservation) Find(db *sqlx.DB, id uint64) (*Reservation, error) {
 	reservation := Reservation{}
@@ -123,16 +374,82 @@ func (Reservation) Destroy(db *sqlx.DB, id uint64) error {
 
 // Insert adds new reservation.
 func (reservation *Reservation) Insert(db *sqlx.DB) error {
-	sql := `INSERT INTO reservations (table_id,guests,email,phone,full_name,time,duration) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
 
-	res, err := db.Exec(sql,
+	if err := reservation.insert(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Create adds new reservation, links it to guest profile and adds required deposit in one transaction.
+func (reservation *Reservation) Create(db *sqlx.DB, deposit *Deposit) error {
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	if err := reservation.linkGuest(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := reservation.insert(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if deposit != nil {
+		deposit.ReservationID = reservation.ID
+
+		if err := deposit.insert(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	reservation.Deposit = deposit
+
+	return nil
+}
+
+func (reservation *Reservation) insert(tx *sqlx.Tx) error {
+	if reservation.State == "" {
+		reservation.State = StateCreated
+	}
+
+	sql := `INSERT INTO reservations
+		(table_id,guest_id,series_id,state,guests,email,phone,full_name,time,duration,
+		occasion,accessibility_needs,children,high_chairs,notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := tx.Exec(sql,
 		reservation.TableID,
+		reservation.GuestID,
+		reservation.SeriesID,
+		reservation.State,
 		reservation.Guests,
 		reservation.Email,
 		reservation.Phone,
 		reservation.FullName,
 		reservation.Time,
 		reservation.Duration,
+		reservation.Occasion,
+		reservation.AccessibilityNeeds,
+		reservation.Children,
+		reservation.HighChairs,
+		reservation.Notes,
 	)
 
 	if err != nil {
@@ -145,45 +462,59 @@ func (reservation *Reservation) Insert(db *sqlx.DB) error {
 		return err
 	}
 
-	createdReservation, err := Reservation.Find(Reservation{}, db, uint64(id))
-	if err != nil {
+	createdReservation := Reservation{}
+
+	if err := tx.Get(&createdReservation, `SELECT * FROM reservations WHERE id = ?;`, id); err != nil {
 		return err
 	}
 
-	*reservation = *createdReservation
+	*reservation = createdReservation
 
 	return nil
 }
 
 // Update puts new values for reservation row fields.
 func (reservation *Reservation) Update(db *sqlx.DB) error {
+	if err := reservation.save(db); err != nil {
+		return err
+	}
+
+	updatedReservation, err := Reservation.Find(Reservation{}, db, uint64(reservation.ID))
+
+	if err != nil {
+		return err
+	}
+
+	*reservation = *updatedReservation
+
+	return nil
+}
+
+// Saves fields of existing reservation without reloading it.
+func (reservation *Reservation) save(execer sqlx.Execer) error {
 	sql := `UPDATE reservations SET
-			table_id = ?, state = ?, guests = ?, email = ?, phone = ?, full_name = ?, time = ?, duration = ?
+			table_id = ?, guest_id = ?, state = ?, detached = ?, guests = ?, email = ?, phone = ?, full_name = ?, time = ?,
+			duration = ?, occasion = ?, accessibility_needs = ?, children = ?, high_chairs = ?, notes = ?
 	 		WHERE id = ?`
 
-	_, err := db.Exec(sql,
+	_, err := execer.Exec(sql,
 		reservation.TableID,
+		reservation.GuestID,
 		reservation.State,
+		reservation.Detached,
 		reservation.Guests,
 		reservation.Email,
 		reservation.Phone,
 		reservation.FullName,
 		reservation.Time,
 		reservation.Duration,
+		reservation.Occasion,
+		reservation.AccessibilityNeeds,
+		reservation.Children,
+		reservation.HighChairs,
+		reservation.Notes,
 		reservation.ID,
 	)
 
-	if err != nil {
-		return err
-	}
-
-	updatedReservation, err := Reservation.Find(Reservation{}, db, uint64(reservation.ID))
-
-	if err != nil {
-		return err
-	}
-
-	*reservation = *updatedReservation
-
-	return nil
+	return err
 }
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanChangeState(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		from     State
+		to       State
+		verified bool
+		allowed  bool
+	}{
+		{StateCreated, StateApproved, true, true},
+		{StateCreated, StateApproved, false, false},
+		{StateCreated, StateCancelled, false, true},
+		{StateCreated, StateSeated, true, false},
+		{StateApproved, StateSeated, true, true},
+		{StateApproved, StateCancelled, true, true},
+		{StateApproved, StateNoShow, true, true},
+		{StateApproved, StateCompleted, true, false},
+		{StateSeated, StateCompleted, true, true},
+		{StateSeated, StateCancelled, true, false},
+		{StateCancelled, StateApproved, true, false},
+		{StateNoShow, StateSeated, true, false},
+		{StateCompleted, StateCancelled, true, false},
+	}
+
+	for _, test := range tests {
+		reservation := Reservation{State: test.from}
+
+		if test.verified {
+			reservation.VerifiedAt = &verifiedAt
+		}
+
+		err := reservation.CanChangeState(test.to)
+
+		if test.allowed {
+			assert.NoError(t, err, "%s -> %s", test.from, test.to)
+		} else {
+			assert.IsType(t, &StateTransitionError{}, err, "%s -> %s", test.from, test.to)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/tools"
+)
+
+// Validate validates and normalizes series fields.
+func (series *ReservationSeries) Validate() error {
+	if series.Guests <= 0 {
+		return errors.New("Invalid number of guests, should be greater that 0")
+	}
+
+	if series.Duration < MinReservationDuration || series.Duration > MaxReservationDuration {
+		return fmt.Errorf("Invalid duration time, should be between %s and %s", MinReservationDuration, MaxReservationDuration)
+	}
+
+	series.Email = NormalizeEmail(series.Email)
+	if !tools.ValidateEmail(series.Email) {
+		return errors.New("Email is invalid")
+	}
+
+	phone, err := NormalizePhone(series.Phone)
+	if err != nil {
+		return err
+	}
+	series.Phone = phone
+
+	series.FullName = strings.TrimSpace(series.FullName)
+	if len(series.FullName) == 0 {
+		return errors.New("Full Name is invalid")
+	}
+
+	if series.Occasion == "" {
+		series.Occasion = OccasionNone
+	}
+	if !series.Occasion.IsValid() {
+		return errors.New("Occasion is invalid")
+	}
+
+	recurrence, err := ParseRecurrence(series.Rule)
+	if err != nil {
+		return fmt.Errorf("Rule is invalid: %s", err)
+	}
+
+	if !series.Until.After(series.StartsAt) {
+		return errors.New("End date should be after start time")
+	}
+
+	if len(series.occurrences(recurrence, MaxOccurrences+1)) > MaxOccurrences {
+		return fmt.Errorf("Series should not have more than %d occurrences, end date should be earlier", MaxOccurrences)
+	}
+
+	return nil
+}
+
+// Format of days, which already have individually changed occurrence.
+const dayFormat = "2006-01-02"
+
+// Returns reservation for occurrence of series.
+func (series *ReservationSeries) reservationAt(occurrence time.Time) Reservation {
+	return Reservation{
+		TableID:  series.TableID,
+		GuestID:  series.GuestID,
+		SeriesID: &series.ID,
+		State:    StateApproved,
+		Guests:   series.Guests,
+		Email:    series.Email,
+		Phone:    series.Phone,
+		FullName: series.FullName,
+		Time:     occurrence,
+		Duration: series.Duration,
+		Occasion: series.Occasion,
+	}
+}
+
+// Returns occurrences of series in restaurant time zone,
+// so weekdays and days of month are the same, as guests see them.
+func (series *ReservationSeries) occurrences(recurrence *Recurrence, limit int) []time.Time {
+	return recurrence.occurrences(series.StartsAt.In(Location), series.Until.In(Location), limit)
+}
+
+// Book creates approved reservations for occurrences, starting not earlier than specified time, in one transaction.
+// Occurrences, which overlap with existing reservations or private events, are reported as conflicts.
+// Days with individually changed reservations of series are not booked again.
+func (series *ReservationSeries) Book(db *sqlx.DB, from time.Time) (*SeriesResult, error) {
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := series.book(tx, from, make([]Reservation, 0))
+
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &changes.Result, nil
+}
+
+// SeriesChanges are reservations of series, which were changed by Rebook.
+type SeriesChanges struct {
+	Result    SeriesResult
+	Created   []Reservation
+	Updated   []Reservation
+	Cancelled []Reservation
+}
+
+// Rebook saves series and applies it to upcoming occurrences, starting not earlier than specified time,
+// in one transaction. Reservations at times, which are still occurrences of series, are updated in place,
+// other upcoming reservations are cancelled and new occurrences are booked.
+// Individually changed reservations are kept.
+func (series *ReservationSeries) Rebook(db *sqlx.DB, from time.Time) (*SeriesChanges, error) {
+	if _, err := ReservationSeries.Find(ReservationSeries{}, db, series.ID); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Beginx()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.NamedExec(updateSeriesQuery, series); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Get(series, "SELECT * FROM reservation_series WHERE id = ?", series.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	upcoming := make([]Reservation, 0)
+
+	sql := `SELECT * FROM reservations WHERE series_id = ? AND NOT detached AND time >= ? AND state IN (?, ?)
+		ORDER BY time FOR UPDATE`
+
+	if err := tx.Select(&upcoming, sql, series.ID, from, StateCreated, StateApproved); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	changes, err := series.book(tx, from, upcoming)
+
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// Books occurrences of series in transaction. Upcoming reservations of series are updated,
+// when they are at time of occurrence, and cancelled otherwise.
+func (series *ReservationSeries) book(tx *sqlx.Tx, from time.Time, upcoming []Reservation) (*SeriesChanges, error) {
+	recurrence, err := ParseRecurrence(series.Rule)
+
+	if err != nil {
+		return nil, err
+	}
+
+	detached, err := series.detachedDays(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := SeriesChanges{
+		Result: SeriesResult{
+			Series:       *series,
+			Reservations: make([]Reservation, 0),
+			Conflicts:    make([]SeriesConflict, 0),
+		},
+		Created:   make([]Reservation, 0),
+		Updated:   make([]Reservation, 0),
+		Cancelled: make([]Reservation, 0),
+	}
+
+	occurrences := make([]time.Time, 0)
+	kept := make(map[int64]Reservation)
+
+	for _, occurrence := range series.occurrences(recurrence, MaxOccurrences) {
+		if occurrence.Before(from) || detached[occurrence.Format(dayFormat)] {
+			continue
+		}
+
+		occurrences = append(occurrences, occurrence)
+		kept[occurrence.Unix()] = Reservation{}
+	}
+
+	// Reservations, which are not occurrences anymore, are cancelled first, so they don't conflict with new ones.
+	for _, reservation := range upcoming {
+		if _, ok := kept[reservation.Time.Unix()]; ok {
+			kept[reservation.Time.Unix()] = reservation
+			continue
+		}
+
+		if err := reservation.CanChangeState(StateCancelled); err != nil {
+			return nil, err
+		}
+
+		reservation.State = StateCancelled
+
+		if err := reservation.save(tx); err != nil {
+			return nil, err
+		}
+
+		changes.Cancelled = append(changes.Cancelled, reservation)
+	}
+
+	for _, occurrence := range occurrences {
+		existing := kept[occurrence.Unix()]
+		reservation := series.reservationAt(occurrence)
+
+		if existing.ID != 0 {
+			reservation.ID = existing.ID
+			reservation.State = existing.State
+			reservation.AccessibilityNeeds = existing.AccessibilityNeeds
+			reservation.Children = existing.Children
+			reservation.HighChairs = existing.HighChairs
+			reservation.Notes = existing.Notes
+		}
+
+		if err := reservation.validateTime(tx); err != nil {
+			if err != errTimeTaken && err != errPrivateEvent {
+				return nil, err
+			}
+
+			// Existing reservation is kept with previous values.
+			conflict := SeriesConflict{Time: occurrence, ReservationID: existing.ID, Reason: err.Error()}
+			changes.Result.Conflicts = append(changes.Result.Conflicts, conflict)
+
+			if existing.ID != 0 {
+				changes.Result.Reservations = append(changes.Result.Reservations, existing)
+			}
+
+			continue
+		}
+
+		if existing.ID == 0 {
+			if err := reservation.insert(tx); err != nil {
+				return nil, err
+			}
+
+			changes.Created = append(changes.Created, reservation)
+		} else {
+			if err := reservation.save(tx); err != nil {
+				return nil, err
+			}
+
+			if err := tx.Get(&reservation, `SELECT * FROM reservations WHERE id = ?`, reservation.ID); err != nil {
+				return nil, err
+			}
+
+			changes.Updated = append(changes.Updated, reservation)
+		}
+
+		changes.Result.Reservations = append(changes.Result.Reservations, reservation)
+	}
+
+	return &changes, nil
+}
+
+// Returns days in restaurant time zone, which have individually changed reservations of series.
+func (series *ReservationSeries) detachedDays(queryer sqlx.Queryer) (map[string]bool, error) {
+	reservations := make([]Reservation, 0)
+
+	sql := `SELECT * FROM reservations WHERE series_id = ? AND detached AND state IN (?, ?, ?)`
+
+	if err := sqlx.Select(queryer, &reservations, sql, series.ID, StateCreated, StateApproved, StateSeated); err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]bool)
+	for _, reservation := range reservations {
+		days[reservation.Time.In(Location).Format(dayFormat)] = true
+	}
+
+	return days, nil
+}
+
+// GetUpcoming returns reservations of series, which have not started yet and could be cancelled.
+func (series *ReservationSeries) GetUpcoming(db *sqlx.DB) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	sql := `SELECT * FROM reservations WHERE series_id = ? AND time >= NOW() AND state IN (?, ?) ORDER BY time`
+
+	if err := db.Select(&reservations, sql, series.ID, StateCreated, StateApproved); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// GetOccurrences returns all reservations of series ordered by time.
+func (series *ReservationSeries) GetOccurrences(db *sqlx.DB) (*[]Reservation, error) {
+	reservations := make([]Reservation, 0)
+
+	if err := db.Select(&reservations, `SELECT * FROM reservations WHERE series_id = ? ORDER BY time`, series.ID); err != nil {
+		return nil, err
+	}
+
+	return &reservations, nil
+}
+
+// GetAll returns list of all reservation series.
+func (ReservationSeries) GetAll(db *sqlx.DB) (*[]ReservationSeries, error) {
+	series := make([]ReservationSeries, 0)
+
+	if err := db.Select(&series, `SELECT * FROM reservation_series ORDER BY id;`); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// Find returns ReservationSeries object with specified ID.
+func (ReservationSeries) Find(db *sqlx.DB, id uint64) (*ReservationSeries, error) {
+	series := ReservationSeries{}
+
+	if err := db.Get(&series, "SELECT * FROM reservation_series WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// Insert adds new reservation series.
+func (series *ReservationSeries) Insert(db *sqlx.DB) error {
+	sqlStatement := `INSERT INTO reservation_series
+		(table_id, guest_id, guests, email, phone, full_name, duration, occasion, rule, starts_at, until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(sqlStatement,
+		series.TableID,
+		series.GuestID,
+		series.Guests,
+		series.Email,
+		series.Phone,
+		series.FullName,
+		series.Duration,
+		series.Occasion,
+		series.Rule,
+		series.StartsAt,
+		series.Until,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	createdSeries, err := ReservationSeries.Find(ReservationSeries{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*series = *createdSeries
+
+	return nil
+}
+
+const updateSeriesQuery = `UPDATE reservation_series SET
+	table_id=:table_id, guest_id=:guest_id, guests=:guests, email=:email, phone=:phone, full_name=:full_name,
+	duration=:duration, occasion=:occasion, rule=:rule, starts_at=:starts_at, until=:until, state=:state
+	WHERE id = :id`
+
+// Update reservation series object in DB.
+func (series *ReservationSeries) Update(db *sqlx.DB) error {
+
+	if _, err := ReservationSeries.Find(ReservationSeries{}, db, series.ID); err != nil {
+		return err
+	}
+
+	_, err := db.NamedExec(updateSeriesQuery, series)
+
+	if err != nil {
+		return err
+	}
+
+	updatedSeries, err := ReservationSeries.Find(ReservationSeries{}, db, series.ID)
+	if err != nil {
+		return err
+	}
+	*series = *updatedSeries
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var seriesColumns = []string{"id", "table_id", "guests", "duration", "rule", "starts_at", "until", "state"}
+
+func TestSeriesOccurrencesInRestaurantLocation(t *testing.T) {
+	location := Location
+	defer func() { Location = location }()
+
+	Location = time.FixedZone("AST", 3*60*60)
+
+	// Thursday 22:30 UTC is already Friday in the restaurant.
+	start := time.Date(2019, 12, 5, 22, 30, 0, 0, time.UTC)
+	series := ReservationSeries{Rule: "FREQ=WEEKLY;BYDAY=FR", StartsAt: start, Until: start.AddDate(0, 0, 14)}
+
+	recurrence, err := ParseRecurrence(series.Rule)
+	assert.NoError(t, err)
+
+	occurrences := series.occurrences(recurrence, MaxOccurrences)
+
+	assert.Len(t, occurrences, 3)
+	for i, occurrence := range occurrences {
+		assert.True(t, start.AddDate(0, 0, 7*i).Equal(occurrence), occurrence.String())
+		assert.Equal(t, time.Friday, occurrence.Weekday())
+	}
+}
+
+func TestDetachedDaysInRestaurantLocation(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	location := Location
+	defer func() { Location = location }()
+
+	Location = time.FixedZone("AST", 3*60*60)
+
+	series := ReservationSeries{ID: 1}
+
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE series_id = \\? AND detached").
+		WithArgs(1, StateCreated, StateApproved, StateSeated).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).
+			AddRow(5, 2, StateApproved, time.Date(2019, 12, 5, 22, 30, 0, 0, time.UTC), 2*time.Hour))
+
+	days, err := series.detachedDays(DB)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"2019-12-06": true}, days)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRebookSeries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	// Series is moved from Fridays to Thursdays.
+	start := time.Date(2019, 12, 5, 18, 0, 0, 0, time.UTC)
+	until := start.AddDate(0, 0, 14)
+	from := start.AddDate(0, 0, 5)
+	series := ReservationSeries{
+		ID: 1, TableID: 2, Guests: 4, Duration: 2 * time.Hour,
+		Rule: "FREQ=WEEKLY;BYDAY=TH", StartsAt: start, Until: until, State: SeriesActive,
+	}
+	seriesRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(seriesColumns).AddRow(1, 2, 4, 2*time.Hour, series.Rule, start, until, SeriesActive)
+	}
+
+	thursday := start.AddDate(0, 0, 7)
+	nextThursday := start.AddDate(0, 0, 14)
+	friday := thursday.AddDate(0, 0, 1)
+
+	mock.ExpectQuery("^SELECT (.+) FROM reservation_series WHERE id = \\?").WithArgs(1).WillReturnRows(seriesRow())
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE reservation_series SET").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM reservation_series WHERE id = \\?").WithArgs(1).WillReturnRows(seriesRow())
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE series_id = \\? AND NOT detached AND time >= \\?").
+		WithArgs(1, from, StateCreated, StateApproved).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).
+			AddRow(11, 2, StateApproved, thursday, 2*time.Hour).
+			AddRow(12, 2, StateApproved, friday, 2*time.Hour))
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE series_id = \\? AND detached").
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+
+	// Reservation on Friday is not an occurrence anymore.
+	mock.ExpectExec("^UPDATE reservations SET").
+		WithArgs(2, nil, StateCancelled, false, 0, "", "", "", friday, 2*time.Hour, "", "", 0, 0, "", 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Reservation on Thursday is updated in place.
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE table_id = \\? AND id <> \\?").
+		WithArgs(2, 11, int64(VerificationTTL.Seconds())).
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	mock.ExpectQuery("^SELECT (.+) FROM private_events").WillReturnRows(sqlmock.NewRows(privateEventColumns))
+	mock.ExpectExec("^UPDATE reservations SET").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").WithArgs(11).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).AddRow(11, 2, StateApproved, thursday, 2*time.Hour))
+
+	// Next Thursday is booked.
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE table_id = \\? AND id <> \\?").
+		WithArgs(2, 0, int64(VerificationTTL.Seconds())).
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	mock.ExpectQuery("^SELECT (.+) FROM private_events").WillReturnRows(sqlmock.NewRows(privateEventColumns))
+	mock.ExpectExec("^INSERT INTO reservations").WillReturnResult(sqlmock.NewResult(13, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM reservations WHERE id = \\?").WithArgs(13).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).AddRow(13, 2, StateApproved, nextThursday, 2*time.Hour))
+	mock.ExpectCommit()
+
+	changes, err := series.Rebook(DB, from)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	if assert.Len(t, changes.Cancelled, 1) {
+		assert.Equal(t, uint64(12), changes.Cancelled[0].ID)
+	}
+	if assert.Len(t, changes.Updated, 1) {
+		assert.Equal(t, uint64(11), changes.Updated[0].ID)
+	}
+	if assert.Len(t, changes.Created, 1) {
+		assert.Equal(t, uint64(13), changes.Created[0].ID)
+	}
+	assert.Len(t, changes.Result.Reservations, 2)
+	assert.Len(t, changes.Result.Conflicts, 0)
+}
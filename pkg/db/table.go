@@ -4,6 +4,15 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// Validate validates table fields.
+func (table *Table) Validate() error {
+	errs := ValidationErrors{}
+
+	errs.check(table.Places > 0, "places", "Places count should be more than 0")
+
+	return errs.err()
+}
+
 // GetAll returns list of all tables.
 func (Table) GetAll(db *sqlx.DB) (*[]Table, error) {
 	tables := make([]Table, 0)
@@ -0,0 +1,189 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Validate validates and normalizes translation fields.
+func (translation *Translation) Validate() error {
+	errs := ValidationErrors{}
+
+	translation.Name = strings.TrimSpace(translation.Name)
+	errs.check(len(translation.Name) != 0, "name", "Name should not be empty")
+
+	translation.Description = strings.TrimSpace(translation.Description)
+
+	return errs.err()
+}
+
+// Returns sql.ErrNoRows, when no rows were deleted.
+func checkDeleted(result sql.Result) error {
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetTranslations returns translations of menu item to all languages.
+func (MenuItem) GetTranslations(db *sqlx.DB, id uint64) (*[]Translation, error) {
+	translations := make([]Translation, 0)
+
+	query := `SELECT lang, name, description FROM menu_translations WHERE menu_item_id = ? ORDER BY lang`
+	if err := db.Select(&translations, query, id); err != nil {
+		return nil, err
+	}
+
+	return &translations, nil
+}
+
+// SaveTranslation creates or replaces translation of menu item.
+func (menuItem *MenuItem) SaveTranslation(db *sqlx.DB, translation Translation) error {
+	query := `INSERT INTO menu_translations (menu_item_id, lang, name, description) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name), description = VALUES(description)`
+
+	_, err := db.Exec(query, menuItem.ID, translation.Lang, translation.Name, translation.Description)
+
+	return err
+}
+
+// DestroyTranslation deletes translation of menu item to specified language.
+func (MenuItem) DestroyTranslation(db *sqlx.DB, id uint64, lang string) error {
+	result, err := db.Exec(`DELETE FROM menu_translations WHERE menu_item_id = ? AND lang = ?`, id, lang)
+
+	if err != nil {
+		return err
+	}
+
+	return checkDeleted(result)
+}
+
+// TranslateMenu replaces name and description of menu items with translations to specified language.
+// Items without translation keep their content.
+func TranslateMenu(db *sqlx.DB, menuItems []MenuItem, lang string) error {
+	if len(menuItems) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(menuItems))
+	for i, menuItem := range menuItems {
+		ids[i] = menuItem.ID
+	}
+
+	query, args, err := sqlx.In(`SELECT menu_item_id, name, description FROM menu_translations
+		WHERE lang = ? AND menu_item_id IN (?)`, lang, ids)
+	if err != nil {
+		return err
+	}
+
+	translations := make([]struct {
+		MenuItemID uint64 `db:"menu_item_id"`
+		Translation
+	}, 0)
+
+	if err := db.Select(&translations, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	byItem := make(map[uint64]Translation)
+	for _, translation := range translations {
+		byItem[translation.MenuItemID] = translation.Translation
+	}
+
+	for i := range menuItems {
+		if translation, ok := byItem[menuItems[i].ID]; ok {
+			menuItems[i].Name = translation.Name
+
+			if translation.Description != "" {
+				menuItems[i].Description = translation.Description
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetTranslations returns translations of menu category to all languages.
+func (MenuCategory) GetTranslations(db *sqlx.DB, id uint64) (*[]Translation, error) {
+	translations := make([]Translation, 0)
+
+	query := `SELECT lang, name FROM category_translations WHERE category_id = ? ORDER BY lang`
+	if err := db.Select(&translations, query, id); err != nil {
+		return nil, err
+	}
+
+	return &translations, nil
+}
+
+// SaveTranslation creates or replaces translation of menu category.
+func (menuCategory *MenuCategory) SaveTranslation(db *sqlx.DB, translation Translation) error {
+	query := `INSERT INTO category_translations (category_id, lang, name) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name)`
+
+	_, err := db.Exec(query, menuCategory.ID, translation.Lang, translation.Name)
+
+	return err
+}
+
+// DestroyTranslation deletes translation of menu category to specified language.
+func (MenuCategory) DestroyTranslation(db *sqlx.DB, id uint64, lang string) error {
+	result, err := db.Exec(`DELETE FROM category_translations WHERE category_id = ? AND lang = ?`, id, lang)
+
+	if err != nil {
+		return err
+	}
+
+	return checkDeleted(result)
+}
+
+// TranslateCategories replaces names of menu categories with translations to specified language.
+// Categories without translation keep their name.
+func TranslateCategories(db *sqlx.DB, categories []MenuCategory, lang string) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+
+	query, args, err := sqlx.In(`SELECT category_id, name FROM category_translations
+		WHERE lang = ? AND category_id IN (?)`, lang, ids)
+	if err != nil {
+		return err
+	}
+
+	translations := make([]struct {
+		CategoryID uint64 `db:"category_id"`
+		Name       string `db:"name"`
+	}, 0)
+
+	if err := db.Select(&translations, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	names := make(map[uint64]string)
+	for _, translation := range translations {
+		names[translation.CategoryID] = translation.Name
+	}
+
+	for i := range categories {
+		if name, ok := names[categories[i].ID]; ok {
+			categories[i].Name = name
+		}
+	}
+
+	return nil
+}
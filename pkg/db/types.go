@@ -4,6 +4,10 @@ import (
 	"time"
 )
 
+import (
+	"github.com/palestine-nights/backend/pkg/money"
+)
+
 // Table represents restaurant table.
 //
 // swagger:parameters model
@@ -33,6 +37,64 @@ const (
 	StateApproved State = "approved"
 	// StateCancelled returns state string of cancelled reservation.
 	StateCancelled State = "cancelled"
+	// StateSeated returns state string of reservation, which guests have arrived.
+	StateSeated State = "seated"
+	// StateCompleted returns state string of finished reservation.
+	StateCompleted State = "completed"
+	// StateNoShow returns state string of approved reservation, which guests have not arrived.
+	StateNoShow State = "no_show"
+)
+
+// Occasion is string representation of reservation occasion.
+// swagger:strfmt occasion
+type Occasion string
+
+const (
+	// OccasionNone is used for reservations without special occasion.
+	OccasionNone Occasion = "none"
+	// OccasionBirthday is used for birthday celebrations.
+	OccasionBirthday Occasion = "birthday"
+	// OccasionAnniversary is used for anniversary celebrations.
+	OccasionAnniversary Occasion = "anniversary"
+	// OccasionBusiness is used for business meals.
+	OccasionBusiness Occasion = "business"
+	// OccasionDate is used for romantic dinners.
+	OccasionDate Occasion = "date"
+	// OccasionOther is used for other celebrations.
+	OccasionOther Occasion = "other"
+)
+
+// Occasions is list of all supported occasions.
+var Occasions = []Occasion{
+	OccasionNone,
+	OccasionBirthday,
+	OccasionAnniversary,
+	OccasionBusiness,
+	OccasionDate,
+	OccasionOther,
+}
+
+// IsValid returns true for supported occasion.
+func (occasion Occasion) IsValid() bool {
+	for _, supported := range Occasions {
+		if occasion == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Length limits of reservation text fields.
+const (
+	MaxAccessibilityNeedsLength = 255
+	MaxNotesLength              = 500
+)
+
+// Limits of reservation duration.
+const (
+	MinReservationDuration = time.Hour
+	MaxReservationDuration = 6 * time.Hour
 )
 
 // Reservation model for table reservation process.
@@ -43,6 +105,10 @@ type Reservation struct {
 	// ID of table, associated with reservation.
 	// required: true
 	TableID uint64 `json:"table_id" db:"table_id"`
+	// ID of guest profile, associated with reservation.
+	GuestID *uint64 `json:"guest_id" db:"guest_id"`
+	// ID of recurring series, which reservation belongs to.
+	SeriesID *uint64 `json:"series_id" db:"series_id"`
 	// Number of people to seat for reservation.
 	// required: true
 	Guests int64 `json:"guests" db:"guests"`
@@ -61,9 +127,46 @@ type Reservation struct {
 	Time time.Time `json:"time" db:"time"`
 	// Duration of the reservation.
 	// required: truee
-	Duration  time.Duration `json:"duration" db:"duration"`
-	CreatedAt time.Time     `json:"-" db:"created_at"`
-	UpdatedAt time.Time     `json:"-" db:"updated_at"`
+	Duration time.Duration `json:"duration" db:"duration"`
+	// Occasion of the visit.
+	Occasion Occasion `json:"occasion" db:"occasion"`
+	// Accessibility needs, e.g. wheelchair access.
+	AccessibilityNeeds string `json:"accessibility_needs" db:"accessibility_needs"`
+	// Number of children among guests.
+	Children int64 `json:"children" db:"children"`
+	// Number of required high chairs.
+	HighChairs int64 `json:"high_chairs" db:"high_chairs"`
+	// Free-text requests of the client.
+	Notes string `json:"notes" db:"notes"`
+	// Flag, whether occurrence of series was changed individually and is kept on series update.
+	Detached bool `json:"detached" db:"detached"`
+	// Time, when client has confirmed email or phone.
+	VerifiedAt *time.Time `json:"verified_at" db:"verified_at"`
+	// Channel to send verification code to: "email" or "sms".
+	VerificationChannel string `json:"verification_channel,omitempty" db:"-"`
+	// Token, which gives the client access to reservation calendar file.
+	Token string `json:"token,omitempty" db:"-"`
+	// Deposit, required for the reservation.
+	Deposit *Deposit `json:"deposit,omitempty" db:"-"`
+	// Time, when reminder was sent to the client.
+	ReminderSentAt *time.Time `json:"-" db:"reminder_sent_at"`
+	CreatedAt      time.Time  `json:"-" db:"created_at"`
+	UpdatedAt      time.Time  `json:"-" db:"updated_at"`
+}
+
+// Verification model for one-time code, which confirms contact of reservation.
+type Verification struct {
+	ID uint64 `db:"id"`
+	// ID of reservation to verify.
+	ReservationID uint64 `db:"reservation_id"`
+	// Channel, the code was sent to.
+	Channel string `db:"channel"`
+	// SHA-256 hash of the code.
+	CodeHash string `db:"code_hash"`
+	// Number of failed attempts.
+	Attempts  int64     `db:"attempts"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
 }
 
 // MenuItem model for menu.
@@ -77,17 +180,127 @@ type MenuItem struct {
 	// Description of the menu item.
 	// required: true
 	Description string `json:"description" db:"description"`
-	// Price of the menu item in Bahrain Dinars.
+	// Price of the menu item in Bahrain Dinars, with at most 3 decimal places.
 	// required: true
-	Price float32 `json:"price" db:"price"`
+	Price money.Money `json:"price" db:"price"`
 	// Category of the menu item.
 	// required: true
 	CategoryID uint64 `json:"category_id" db:"category_id"`
 	// Image URL for the menu item.
 	// required: true
 	ImageURL string `json:"image_url" db:"image_url"`
+	// Thumbnail URL of uploaded image, it is cleared, when image URL is changed.
+	ThumbnailURL string `json:"thumbnail_url" db:"thumbnail_url"`
 	// Active flag for the menu item.
 	// required: true
+	Active bool `json:"active" db:"active"`
+	// Option groups of the menu item, e.g. size or add-ons.
+	OptionGroups []OptionGroup `json:"option_groups" db:"-"`
+	// Codes of allergen and dietary tags of the menu item.
+	Tags []string `json:"tags" db:"-"`
+	// Periods, when the menu item could be ordered, always if empty.
+	Availability []AvailabilityWindow `json:"availability" db:"-"`
+	CreatedAt    time.Time            `json:"-" db:"created_at"`
+	UpdatedAt    time.Time            `json:"-" db:"updated_at"`
+}
+
+// TagKind is string representation of kind of dietary tag.
+// swagger:strfmt tagKind
+type TagKind string
+
+const (
+	// TagKindAllergen is used for allergens, which menu item contains, e.g. nuts.
+	TagKindAllergen TagKind = "allergen"
+	// TagKindDiet is used for diets, which menu item suits, e.g. vegan.
+	TagKindDiet TagKind = "diet"
+	// TagKindSpice is used for spice levels.
+	TagKindSpice TagKind = "spice"
+)
+
+// DietaryTag model for allergen or dietary label of menu items.
+//
+// swagger:model
+type DietaryTag struct {
+	ID uint64 `json:"id" db:"id"`
+	// Unique code of the tag, used in filters, e.g. "gluten-free".
+	// required: true
+	Code string `json:"code" db:"code"`
+	// Name of the tag to show.
+	// required: true
+	Name string `json:"name" db:"name"`
+	// Kind of the tag: "allergen", "diet" or "spice".
+	// required: true
+	Kind      TagKind   `json:"kind" db:"kind"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+	UpdatedAt time.Time `json:"-" db:"updated_at"`
+}
+
+// Translation model for content of menu item or category in other language.
+//
+// swagger:model
+type Translation struct {
+	// Language code, e.g. "ar".
+	Lang string `json:"lang" db:"lang"`
+	// Translated name.
+	// required: true
+	Name string `json:"name" db:"name"`
+	// Translated description, only menu items have it.
+	Description string `json:"description" db:"description"`
+}
+
+// OptionGroupType is string representation of choice type of option group.
+// swagger:strfmt optionGroupType
+type OptionGroupType string
+
+const (
+	// OptionGroupSingle allows to choose one option, e.g. size.
+	OptionGroupSingle OptionGroupType = "single"
+	// OptionGroupMulti allows to choose several options, e.g. add-ons.
+	OptionGroupMulti OptionGroupType = "multi"
+)
+
+// OptionGroup model for group of menu item modifiers.
+//
+// swagger:model
+type OptionGroup struct {
+	ID uint64 `json:"id" db:"id"`
+	// ID of menu item, the group belongs to.
+	// required: true
+	MenuItemID uint64 `json:"menu_item_id" db:"menu_item_id"`
+	// Name of the group, e.g. "Size".
+	// required: true
+	Name string `json:"name" db:"name"`
+	// Choice type: "single" or "multi".
+	// required: true
+	Type OptionGroupType `json:"type" db:"type"`
+	// Minimal number of chosen options, 0 for optional group.
+	MinChoices int64 `json:"min_choices" db:"min_choices"`
+	// Maximal number of chosen options, 0 for unlimited.
+	MaxChoices int64 `json:"max_choices" db:"max_choices"`
+	// Order of the group in menu item.
+	Order uint64 `json:"order" db:"order"`
+	// Options of the group.
+	Options   []MenuOption `json:"options" db:"-"`
+	CreatedAt time.Time    `json:"-" db:"created_at"`
+	UpdatedAt time.Time    `json:"-" db:"updated_at"`
+}
+
+// MenuOption model for single modifier of menu item.
+//
+// swagger:model
+type MenuOption struct {
+	ID uint64 `json:"id" db:"id"`
+	// ID of option group, the option belongs to.
+	// required: true
+	GroupID uint64 `json:"group_id" db:"group_id"`
+	// Name of the option, e.g. "Large".
+	// required: true
+	Name string `json:"name" db:"name"`
+	// Difference with price of menu item, could be negative.
+	PriceDelta money.Money `json:"price_delta" db:"price_delta"`
+	// Order of the option in the group.
+	Order uint64 `json:"order" db:"order"`
+	// Active flag for the option.
 	Active    bool      `json:"active" db:"active"`
 	CreatedAt time.Time `json:"-" db:"created_at"`
 	UpdatedAt time.Time `json:"-" db:"updated_at"`
@@ -103,7 +316,358 @@ type MenuCategory struct {
 	Name string `json:"name" db:"name"`
 	// Order of this category in categories list.
 	// required: true
-	Order     uint64    `json:"order" db:"order"`
+	Order uint64 `json:"order" db:"order"`
+	// Parent category of sub-section, top level category if empty.
+	ParentID *uint64 `json:"parent_id" db:"parent_id"`
+	// Periods, when items of the category could be ordered, always if empty.
+	Availability []AvailabilityWindow `json:"availability" db:"-"`
+	CreatedAt    time.Time            `json:"-" db:"created_at"`
+	UpdatedAt    time.Time            `json:"-" db:"updated_at"`
+}
+
+// AvailabilityWindow is period, when menu item or category could be ordered,
+// e.g. breakfast on weekdays or seasonal special.
+//
+// swagger:model
+type AvailabilityWindow struct {
+	ID         uint64  `json:"-" db:"id"`
+	MenuItemID *uint64 `json:"-" db:"menu_item_id"`
+	CategoryID *uint64 `json:"-" db:"category_id"`
+	// Days of week ("mon", "tue", ..., "sun"), all days if empty.
+	Weekdays StringList `json:"weekdays" db:"weekdays"`
+	// Start of time range in "15:04" format, start of the day if empty.
+	StartTime string `json:"start_time" db:"start_time"`
+	// End of time range in "15:04" format, end of the day if empty.
+	// Range, which ends before its start, lasts past midnight, e.g. 22:00-02:00.
+	EndTime string `json:"end_time" db:"end_time"`
+	// First date of the window, unlimited if empty.
+	StartsOn *time.Time `json:"starts_on" db:"starts_on"`
+	// Last date of the window, unlimited if empty.
+	EndsOn *time.Time `json:"ends_on" db:"ends_on"`
+}
+
+// MenuPrice model for price of menu item since specified time.
+// Prices are kept after change, so receipts could be reconciled with old prices.
+//
+// swagger:model
+type MenuPrice struct {
+	ID         uint64 `json:"id" db:"id"`
+	MenuItemID uint64 `json:"menu_item_id" db:"menu_item_id"`
+	// Price of the menu item in Bahrain Dinars.
+	// required: true
+	Price money.Money `json:"price" db:"price"`
+	// Time, since which the price is effective.
+	// required: true
+	EffectiveFrom time.Time `json:"effective_from" db:"effective_from"`
+	// Applied flag, false for scheduled price change, which is not effective yet.
+	Applied   bool      `json:"applied" db:"applied"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+}
+
+// JobLock model for DB-based locking of background jobs between replicas.
+type JobLock struct {
+	// Name of the locked job.
+	Name string `db:"name"`
+	// Identifier of the process, which holds the lock.
+	Owner string `db:"owner"`
+	// Time, when lock expires.
+	LockedUntil time.Time `db:"locked_until"`
+}
+
+// Webhook model for subscription to reservation and menu events.
+//
+// swagger:model
+type Webhook struct {
+	ID uint64 `json:"id" db:"id"`
+	// URL, which receives POST requests with JSON payload.
+	// required: true
+	URL string `json:"url" db:"url"`
+	// Secret to sign payload with HMAC-SHA256.
+	// It is write-only and never returned in responses, current secret is kept, when it is empty on update.
+	// required: true
+	Secret string `json:"secret,omitempty" db:"secret"`
+	// List of subscribed events, all events if empty.
+	Events StringList `json:"events" db:"events"`
+	// Active flag for the webhook.
+	// required: true
+	Active    bool      `json:"active" db:"active"`
 	CreatedAt time.Time `json:"-" db:"created_at"`
 	UpdatedAt time.Time `json:"-" db:"updated_at"`
 }
+
+// WebhookDelivery model for log of webhook delivery attempts.
+//
+// swagger:model
+type WebhookDelivery struct {
+	ID        uint64 `json:"id" db:"id"`
+	WebhookID uint64 `json:"webhook_id" db:"webhook_id"`
+	// Unique ID of the event, same for all attempts.
+	EventID string `json:"event_id" db:"event_id"`
+	// Name of the event.
+	Event string `json:"event" db:"event"`
+	// JSON payload.
+	Payload string `json:"payload" db:"payload"`
+	// Number of the attempt, starting from 1.
+	Attempt int64 `json:"attempt" db:"attempt"`
+	// HTTP status code of response, 0 if request failed.
+	StatusCode int64 `json:"status_code" db:"status_code"`
+	// Error message of failed attempt.
+	Error string `json:"error" db:"error"`
+	// Flag, whether receiver has accepted payload.
+	Delivered bool `json:"delivered" db:"delivered"`
+	// Time of scheduled attempt, empty when attempt is done.
+	NextAttemptAt *time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DepositStatus is string representation of deposit status.
+// swagger:strfmt deposit_status
+type DepositStatus string
+
+const (
+	// DepositRequired returns status of deposit, which is not paid yet.
+	DepositRequired DepositStatus = "required"
+	// DepositPending returns status of deposit, which payment is in progress.
+	DepositPending DepositStatus = "pending"
+	// DepositPaid returns status of paid deposit.
+	DepositPaid DepositStatus = "paid"
+	// DepositRefunded returns status of deposit, returned to the client.
+	DepositRefunded DepositStatus = "refunded"
+	// DepositForfeited returns status of deposit, kept by restaurant after no-show.
+	DepositForfeited DepositStatus = "forfeited"
+)
+
+// Deposit model for prepayment of reservation.
+//
+// swagger:model
+type Deposit struct {
+	ID            uint64 `json:"id" db:"id"`
+	ReservationID uint64 `json:"reservation_id" db:"reservation_id"`
+	// Amount of deposit in Bahrain Dinars.
+	Amount money.Money   `json:"amount" db:"amount"`
+	Status DepositStatus `json:"status" db:"status"`
+	// Reason, why deposit is required, e.g. no-show history.
+	Reason string `json:"reason,omitempty" db:"reason"`
+	// Reference of the payment in payment provider.
+	ProviderRef string    `json:"-" db:"provider_ref"`
+	CreatedAt   time.Time `json:"-" db:"created_at"`
+	UpdatedAt   time.Time `json:"-" db:"updated_at"`
+}
+
+// DepositRule model for conditions, when reservation requires deposit.
+//
+// swagger:model
+type DepositRule struct {
+	ID uint64 `json:"id" db:"id"`
+	// Minimal number of guests, starting from which deposit is required.
+	// required: true
+	MinGuests int64 `json:"min_guests" db:"min_guests"`
+	// Days of week ("mon", "tue", ..., "sun"), all days if empty.
+	Weekdays StringList `json:"weekdays" db:"weekdays"`
+	// First date of the rule, unlimited if empty.
+	StartsOn *time.Time `json:"starts_on" db:"starts_on"`
+	// Last date of the rule, unlimited if empty.
+	EndsOn *time.Time `json:"ends_on" db:"ends_on"`
+	// Deposit amount per guest in Bahrain Dinars.
+	// required: true
+	AmountPerGuest money.Money `json:"amount_per_guest" db:"amount_per_guest"`
+	// Active flag for the rule.
+	// required: true
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+	UpdatedAt time.Time `json:"-" db:"updated_at"`
+}
+
+// Guest model for profile of restaurant client, deduplicated by phone and email.
+//
+// swagger:model
+type Guest struct {
+	ID uint64 `json:"id" db:"id"`
+	// Full Name of the guest.
+	// required: true
+	FullName string `json:"full_name" db:"full_name"`
+	// Normalized email of the guest.
+	Email string `json:"email" db:"email"`
+	// Phone of the guest in E164 format.
+	Phone string `json:"phone" db:"phone"`
+	// Staff notes about the guest.
+	Notes string `json:"notes" db:"notes"`
+	// Tags of the guest, e.g. "vip", "allergy-nuts".
+	Tags      StringList `json:"tags" db:"tags"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"-" db:"updated_at"`
+}
+
+// GuestProfile model for guest with visit history.
+//
+// swagger:model
+type GuestProfile struct {
+	Guest
+	// Number of completed visits.
+	Visits int64 `json:"visits"`
+	// Number of reservations, which guest has not arrived to.
+	NoShows int64 `json:"no_shows"`
+	// Number of cancelled reservations.
+	Cancellations int64 `json:"cancellations"`
+	// All reservations of the guest, latest first.
+	Reservations []Reservation `json:"reservations"`
+}
+
+// BlockEntry model for contact or guest, which is not allowed to book.
+//
+// swagger:model
+type BlockEntry struct {
+	ID uint64 `json:"id" db:"id"`
+	// Blocked phone.
+	Phone string `json:"phone" db:"phone"`
+	// Blocked email.
+	Email string `json:"email" db:"email"`
+	// Blocked guest profile.
+	GuestID *uint64 `json:"guest_id" db:"guest_id"`
+	// Reason of blocking, returned in rejection.
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"-" db:"updated_at"`
+}
+
+// RestrictionAction is action, applied to restricted booking.
+type RestrictionAction string
+
+const (
+	// RestrictionReject rejects booking.
+	RestrictionReject RestrictionAction = "reject"
+	// RestrictionDeposit accepts booking only with deposit.
+	RestrictionDeposit RestrictionAction = "deposit"
+)
+
+// Restriction is result of booking restrictions check.
+type Restriction struct {
+	Action RestrictionAction
+	// Explanation for the client.
+	Reason string
+}
+
+// NoShowPolicy is automatic restriction for guests with several no-shows.
+type NoShowPolicy struct {
+	// Number of no-shows, starting from which booking is restricted, disabled if 0.
+	Limit int64
+	// Period, in which no-shows are counted.
+	Window time.Duration
+	// Action for restricted booking.
+	Action RestrictionAction
+	// Deposit per guest, when action is "deposit".
+	DepositPerGuest money.Money
+}
+
+// SeriesState is string representation of recurring series state.
+// swagger:strfmt series_state
+type SeriesState string
+
+const (
+	// SeriesActive returns state of series, which occurrences are booked.
+	SeriesActive SeriesState = "active"
+	// SeriesCancelled returns state of cancelled series.
+	SeriesCancelled SeriesState = "cancelled"
+)
+
+// ReservationSeries model for recurring reservations of regular clients.
+//
+// swagger:model
+type ReservationSeries struct {
+	ID uint64 `json:"id" db:"id"`
+	// ID of table, associated with reservations.
+	// required: true
+	TableID uint64 `json:"table_id" db:"table_id"`
+	// ID of guest profile, associated with reservations.
+	GuestID *uint64 `json:"guest_id" db:"guest_id"`
+	// Number of people to seat.
+	// required: true
+	Guests int64 `json:"guests" db:"guests"`
+	// Email of the client.
+	// required: true
+	Email string `json:"email" db:"email"`
+	// Phone of the client.
+	// required: true
+	Phone string `json:"phone" db:"phone"`
+	// Full Name of the client.
+	// required: true
+	FullName string `json:"full_name" db:"full_name"`
+	// Duration of every reservation.
+	// required: true
+	Duration time.Duration `json:"duration" db:"duration"`
+	// Occasion of every reservation.
+	Occasion Occasion `json:"occasion" db:"occasion"`
+	// Recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=TH" or "FREQ=MONTHLY;BYMONTHDAY=1".
+	// required: true
+	Rule string `json:"rule" db:"rule"`
+	// Time of the first reservation.
+	// required: true
+	StartsAt time.Time `json:"starts_at" db:"starts_at"`
+	// Last time, when reservation could start.
+	// required: true
+	Until     time.Time   `json:"until" db:"until"`
+	State     SeriesState `json:"state" db:"state"`
+	CreatedAt time.Time   `json:"-" db:"created_at"`
+	UpdatedAt time.Time   `json:"-" db:"updated_at"`
+}
+
+// SeriesConflict model for occurrence, which could not be booked.
+//
+// swagger:model
+type SeriesConflict struct {
+	// Time of the occurrence.
+	Time time.Time `json:"time"`
+	// ID of the existing reservation, when occurrence was not updated.
+	ReservationID uint64 `json:"reservation_id,omitempty"`
+	// Reason of the conflict.
+	Reason string `json:"reason"`
+}
+
+// SeriesResult model for series with its occurrences and conflicts.
+//
+// swagger:model
+type SeriesResult struct {
+	Series       ReservationSeries `json:"series"`
+	Reservations []Reservation     `json:"reservations"`
+	Conflicts    []SeriesConflict  `json:"conflicts"`
+}
+
+// PrivateEvent model for private party or buyout, which blocks tables for a time range.
+//
+// swagger:model
+type PrivateEvent struct {
+	ID uint64 `json:"id" db:"id"`
+	// Name of the event.
+	// required: true
+	Name string `json:"name" db:"name"`
+	// Full Name of the organizer.
+	// required: true
+	ContactName string `json:"contact_name" db:"contact_name"`
+	// Email of the organizer.
+	ContactEmail string `json:"contact_email" db:"contact_email"`
+	// Phone of the organizer.
+	ContactPhone string `json:"contact_phone" db:"contact_phone"`
+	// Start time of the event.
+	// required: true
+	StartsAt time.Time `json:"starts_at" db:"starts_at"`
+	// Finish time of the event.
+	// required: true
+	EndsAt time.Time `json:"ends_at" db:"ends_at"`
+	// Whole venue is blocked.
+	AllTables bool `json:"all_tables" db:"all_tables"`
+	// IDs of blocked tables, when not whole venue is blocked.
+	TableIDs  []uint64  `json:"table_ids" db:"-"`
+	Notes     string    `json:"notes" db:"notes"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+	UpdatedAt time.Time `json:"-" db:"updated_at"`
+}
+
+// FieldError model for validation error of single field.
+//
+// swagger:model
+type FieldError struct {
+	// Name of invalid field.
+	Field string `json:"field"`
+	// Error message.
+	Error string `json:"error"`
+}
@@ -0,0 +1,29 @@
+package db
+
+// ValidationErrors is error of model validation with errors of every invalid field.
+type ValidationErrors []FieldError
+
+// Error returns message of the first invalid field.
+func (errs ValidationErrors) Error() string {
+	if len(errs) == 0 {
+		return "Validation failed"
+	}
+
+	return errs[0].Error
+}
+
+// Adds error of the field, when condition is not met.
+func (errs *ValidationErrors) check(ok bool, field, message string) {
+	if !ok {
+		*errs = append(*errs, FieldError{Field: field, Error: message})
+	}
+}
+
+// Returns nil, when all fields are valid.
+func (errs ValidationErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// Models with field validation.
+type validator interface {
+	Validate() error
+}
+
+func TestValidate(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	validReservation := func() *Reservation {
+		return &Reservation{Guests: 4, Children: 1, Time: tomorrow, Duration: 2 * time.Hour}
+	}
+
+	withReservation := func(change func(*Reservation)) *Reservation {
+		reservation := validReservation()
+		change(reservation)
+		return reservation
+	}
+
+	tests := []struct {
+		name   string
+		model  validator
+		fields []string
+	}{
+		{"valid table", &Table{Places: 4}, nil},
+		{"table without places", &Table{Places: 0}, []string{"places"}},
+		{"valid menu item", &MenuItem{Name: "Hummus", Price: money.Money(1500)}, nil},
+		{"menu item with blank name", &MenuItem{Name: "  ", Price: money.Money(1500)}, []string{"name"}},
+		{"free menu item", &MenuItem{Name: "Hummus"}, []string{"price"}},
+		{"menu item without fields", &MenuItem{}, []string{"name", "price"}},
+		{"valid category", &MenuCategory{Name: "Starters"}, nil},
+		{"category without name", &MenuCategory{}, []string{"name"}},
+		{"valid single choice group", &OptionGroup{MenuItemID: 1, Name: "Size", MinChoices: 1}, nil},
+		{"valid multi choice group", &OptionGroup{MenuItemID: 1, Name: "Add-ons", Type: OptionGroupMulti, MaxChoices: 3}, nil},
+		{"group without menu item", &OptionGroup{Name: "Size"}, []string{"menu_item_id"}},
+		{"group with unknown type", &OptionGroup{MenuItemID: 1, Name: "Size", Type: "any"}, []string{"type"}},
+		{"single choice group with 2 required choices", &OptionGroup{MenuItemID: 1, Name: "Size", MinChoices: 2}, []string{"min_choices"}},
+		{"multi choice group with max less than min", &OptionGroup{
+			MenuItemID: 1, Name: "Add-ons", Type: OptionGroupMulti, MinChoices: 2, MaxChoices: 1,
+		}, []string{"max_choices"}},
+		{"valid option", &MenuOption{GroupID: 1, Name: "Large", PriceDelta: money.Money(500)}, nil},
+		{"option without group and name", &MenuOption{}, []string{"group_id", "name"}},
+		{"valid dietary tag", &DietaryTag{Code: " Gluten-Free ", Name: "Gluten-free", Kind: TagKindDiet}, nil},
+		{"dietary tag with invalid code and kind", &DietaryTag{Code: "no nuts", Name: "No nuts", Kind: "label"}, []string{"code", "kind"}},
+		{"valid price change", &MenuPrice{Price: money.Money(2000), EffectiveFrom: tomorrow}, nil},
+		{"free price change in the past", &MenuPrice{EffectiveFrom: tomorrow.Add(-48 * time.Hour)}, []string{"price", "effective_from"}},
+		{"valid reservation", validReservation(), nil},
+		{"reservation without guests", withReservation(func(r *Reservation) {
+			r.Guests, r.Children = 0, 0
+		}), []string{"guests"}},
+		{"short reservation", withReservation(func(r *Reservation) {
+			r.Duration = 30 * time.Minute
+		}), []string{"duration"}},
+		{"long reservation", withReservation(func(r *Reservation) {
+			r.Duration = 7 * time.Hour
+		}), []string{"duration"}},
+		{"reservation in the past", withReservation(func(r *Reservation) {
+			r.Time = time.Now().Add(-time.Hour)
+		}), []string{"time"}},
+		{"reservation with unknown occasion", withReservation(func(r *Reservation) {
+			r.Occasion = "wedding"
+		}), []string{"occasion"}},
+		{"reservation with more children than guests", withReservation(func(r *Reservation) {
+			r.Children, r.HighChairs = 5, 2
+		}), []string{"children"}},
+		{"reservation with more high chairs than children", withReservation(func(r *Reservation) {
+			r.HighChairs = 2
+		}), []string{"high_chairs"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.model.Validate()
+
+			if test.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			fieldErrors, ok := err.(ValidationErrors)
+			if !assert.True(t, ok, "error should be ValidationErrors") {
+				return
+			}
+
+			fields := make([]string, 0)
+			for _, fieldError := range fieldErrors {
+				fields = append(fields, fieldError.Field)
+			}
+
+			assert.Equal(t, test.fields, fields)
+			assert.Equal(t, fieldErrors[0].Error, err.Error())
+		})
+	}
+}
+
+func TestValidateNormalizes(t *testing.T) {
+	menuItem := MenuItem{Name: " Hummus ", Price: money.Money(1500)}
+	reservation := Reservation{Guests: 2, Time: time.Now().Add(time.Hour), Duration: time.Hour, Notes: " Window seat "}
+
+	assert.NoError(t, menuItem.Validate())
+	assert.NoError(t, reservation.Validate())
+
+	assert.Equal(t, "Hummus", menuItem.Name)
+	assert.Equal(t, OccasionNone, reservation.Occasion)
+	assert.Equal(t, "Window seat", reservation.Notes)
+}
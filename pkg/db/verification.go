@@ -0,0 +1,176 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// VerificationTTL is period, during which verification code is valid.
+// Unverified reservations do not block table after this period.
+var VerificationTTL = 15 * time.Minute
+
+// MaxVerificationAttempts is number of failed attempts, after which code is no longer accepted.
+const MaxVerificationAttempts = 5
+
+// VerificationResendInterval is period, after which code of reservation could be sent again.
+var VerificationResendInterval = time.Minute
+
+// MaxVerificationSends is number of codes, which could be sent for one reservation.
+const MaxVerificationSends = 5
+
+var (
+	// ErrVerificationExpired is returned, when code has expired or was not requested.
+	ErrVerificationExpired = errors.New("Verification code has expired")
+	// ErrVerificationAttempts is returned, when too many invalid codes were entered.
+	ErrVerificationAttempts = errors.New("Too many verification attempts")
+	// ErrVerificationCode is returned, when code does not match.
+	ErrVerificationCode = errors.New("Verification code is invalid")
+	// ErrVerificationResend is returned, when code was sent recently or too many codes were sent.
+	ErrVerificationResend = errors.New("Verification code could not be sent again yet")
+)
+
+func hashVerificationCode(reservationID uint64, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", reservationID, code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewVerification generates one-time code for reservation.
+// Returns verification object to store and plain code to send to the client.
+func NewVerification(reservation *Reservation, channel string) (*Verification, string, error) {
+	number, err := rand.Int(rand.Reader, big.NewInt(1000000))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	code := fmt.Sprintf("%06d", number.Int64())
+
+	verification := Verification{
+		ReservationID: reservation.ID,
+		Channel:       channel,
+		CodeHash:      hashVerificationCode(reservation.ID, code),
+		ExpiresAt:     time.Now().Add(VerificationTTL),
+	}
+
+	return &verification, code, nil
+}
+
+// Insert adds new verification.
+func (verification *Verification) Insert(db *sqlx.DB) error {
+	sql := `INSERT INTO verifications (reservation_id, channel, code_hash, expires_at) VALUES (?, ?, ?, ?)`
+
+	result, err := db.Exec(sql,
+		verification.ReservationID,
+		verification.Channel,
+		verification.CodeHash,
+		verification.ExpiresAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	verification.ID = uint64(id)
+
+	return nil
+}
+
+// FindLast returns the latest verification of reservation.
+func (Verification) FindLast(db *sqlx.DB, reservationID uint64) (*Verification, error) {
+	verification := Verification{}
+
+	sql := `SELECT * FROM verifications WHERE reservation_id = ? ORDER BY id DESC LIMIT 1`
+
+	if err := db.Get(&verification, sql, reservationID); err != nil {
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// CanResend returns ErrVerificationResend, when code of reservation was sent recently
+// or limit of sent codes is reached.
+func (Verification) CanResend(db *sqlx.DB, reservationID uint64) error {
+	sent := struct {
+		Total  int64 `db:"total"`
+		Recent int64 `db:"recent"`
+	}{}
+
+	sql := `SELECT COUNT(*) AS total, COALESCE(SUM(created_at > NOW() - INTERVAL ? SECOND), 0) AS recent
+		FROM verifications WHERE reservation_id = ?`
+
+	if err := db.Get(&sent, sql, int64(VerificationResendInterval.Seconds()), reservationID); err != nil {
+		return err
+	}
+
+	if sent.Total >= MaxVerificationSends || sent.Recent != 0 {
+		return ErrVerificationResend
+	}
+
+	return nil
+}
+
+// Check counts attempt and compares code with stored hash.
+// Attempt is counted before comparison, so concurrent requests can't exceed the limit.
+func (verification *Verification) Check(db *sqlx.DB, code string) error {
+	if time.Now().After(verification.ExpiresAt) {
+		return ErrVerificationExpired
+	}
+
+	sql := `UPDATE verifications SET attempts = attempts + 1 WHERE id = ? AND attempts < ?`
+
+	result, err := db.Exec(sql, verification.ID, MaxVerificationAttempts)
+
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrVerificationAttempts
+	}
+
+	verification.Attempts++
+
+	hash := hashVerificationCode(verification.ReservationID, code)
+
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(verification.CodeHash)) != 1 {
+		return ErrVerificationCode
+	}
+
+	return nil
+}
+
+// MarkVerified saves time, when client has confirmed contact.
+func (reservation *Reservation) MarkVerified(db *sqlx.DB) error {
+	now := time.Now()
+
+	if _, err := db.Exec(`UPDATE reservations SET verified_at = ? WHERE id = ?`, now, reservation.ID); err != nil {
+		return err
+	}
+
+	reservation.VerifiedAt = &now
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestVerificationCheck(t *testing.T) {
+	reservation := Reservation{ID: 42}
+
+	verification, code, err := NewVerification(&reservation, "sms")
+	assert.NoError(t, err)
+	verification.ID = 7
+
+	tests := []struct {
+		name     string
+		code     string
+		affected int64
+		expected error
+	}{
+		{name: "valid code", code: code, affected: 1, expected: nil},
+		{name: "wrong code", code: "abcdef", affected: 1, expected: ErrVerificationCode},
+		{name: "attempts limit", code: code, affected: 0, expected: ErrVerificationAttempts},
+	}
+
+	for _, test := range tests {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		DB := sqlx.NewDb(mockDB, "sqlmock")
+
+		mock.ExpectExec("^UPDATE verifications SET attempts = attempts \\+ 1 WHERE id = \\? AND attempts < \\?").
+			WithArgs(7, MaxVerificationAttempts).
+			WillReturnResult(sqlmock.NewResult(0, test.affected))
+
+		check := *verification
+
+		assert.Equal(t, test.expected, check.Check(DB, test.code), test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		DB.Close()
+	}
+}
+
+func TestVerificationCheckExpired(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	reservation := Reservation{ID: 42}
+
+	verification, code, err := NewVerification(&reservation, "sms")
+	assert.NoError(t, err)
+	verification.ExpiresAt = time.Now().Add(-time.Second)
+
+	// Expired code is rejected without counting attempt.
+	assert.Equal(t, ErrVerificationExpired, verification.Check(DB, code))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
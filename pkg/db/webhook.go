@@ -0,0 +1,197 @@
+package db
+
+import (
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// GetAll returns list of all webhooks.
+func (Webhook) GetAll(db *sqlx.DB) (*[]Webhook, error) {
+	webhooks := make([]Webhook, 0)
+
+	if err := db.Select(&webhooks, `SELECT * FROM webhooks;`); err != nil {
+		return nil, err
+	}
+
+	return &webhooks, nil
+}
+
+// GetSubscribed returns active webhooks, subscribed to specified event.
+func (Webhook) GetSubscribed(db *sqlx.DB, event string) (*[]Webhook, error) {
+	webhooks := make([]Webhook, 0)
+
+	active := make([]Webhook, 0)
+	if err := db.Select(&active, `SELECT * FROM webhooks WHERE active = TRUE;`); err != nil {
+		return nil, err
+	}
+
+	for _, webhook := range active {
+		if len(webhook.Events) == 0 || webhook.Events.Contains(event) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return &webhooks, nil
+}
+
+// Find returns Webhook object with specified ID.
+func (Webhook) Find(db *sqlx.DB, id uint64) (*Webhook, error) {
+	webhook := Webhook{}
+
+	if err := db.Get(&webhook, "SELECT * FROM webhooks WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// Destroy webhook with specified ID.
+func (Webhook) Destroy(db *sqlx.DB, id uint64) error {
+
+	if _, err := Webhook.Find(Webhook{}, db, id); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM webhooks WHERE id = ?;`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update webhook object in DB.
+func (webhook *Webhook) Update(db *sqlx.DB) error {
+
+	if _, err := Webhook.Find(Webhook{}, db, webhook.ID); err != nil {
+		return err
+	}
+
+	query := `UPDATE webhooks SET url=:url, secret=:secret, events=:events, active=:active WHERE id = :id`
+	_, err := db.NamedExec(query, webhook)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Insert adds new webhook.
+func (webhook *Webhook) Insert(db *sqlx.DB) error {
+	sqlStatement := `INSERT INTO webhooks (url, secret, events, active) VALUES (?, ?, ?, ?);`
+
+	result, err := db.Exec(sqlStatement, webhook.URL, webhook.Secret, webhook.Events, webhook.Active)
+
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	createdWebhook, err := Webhook.Find(Webhook{}, db, uint64(id))
+	if err != nil {
+		return err
+	}
+	*webhook = *createdWebhook
+
+	return nil
+}
+
+// GetByWebhook returns latest delivery attempts of the webhook.
+func (WebhookDelivery) GetByWebhook(db *sqlx.DB, webhookID uint64, limit uint64) (*[]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0)
+
+	sql := `SELECT * FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`
+
+	if err := db.Select(&deliveries, sql, webhookID, limit); err != nil {
+		return nil, err
+	}
+
+	return &deliveries, nil
+}
+
+// GetDue returns delivery attempts, which are scheduled not later than specified time.
+func (WebhookDelivery) GetDue(db *sqlx.DB, now time.Time, limit uint64) (*[]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0)
+
+	sql := `SELECT * FROM webhook_deliveries WHERE next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`
+
+	if err := db.Select(&deliveries, sql, now, limit); err != nil {
+		return nil, err
+	}
+
+	return &deliveries, nil
+}
+
+// Claim reserves due attempt until specified time, so it is not started twice.
+// Attempt, which was interrupted, becomes due again after this time.
+// Returns false, when attempt is not due or was already claimed.
+func (delivery *WebhookDelivery) Claim(db *sqlx.DB, now time.Time, until time.Time) (bool, error) {
+	sql := `UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND next_attempt_at <= ?`
+
+	result, err := db.Exec(sql, until, delivery.ID, now)
+
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// SaveResult saves response of the attempt and marks it as done.
+func (delivery *WebhookDelivery) SaveResult(db *sqlx.DB) error {
+	sql := `UPDATE webhook_deliveries SET status_code = ?, error = ?, delivered = ?, next_attempt_at = NULL WHERE id = ?`
+
+	if _, err := db.Exec(sql, delivery.StatusCode, delivery.Error, delivery.Delivered, delivery.ID); err != nil {
+		return err
+	}
+
+	delivery.NextAttemptAt = nil
+
+	return nil
+}
+
+// Insert adds delivery attempt to the log.
+func (delivery *WebhookDelivery) Insert(db *sqlx.DB) error {
+	sqlStatement := `INSERT INTO webhook_deliveries
+		(webhook_id, event_id, event, payload, attempt, status_code, error, delivered, next_attempt_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(sqlStatement,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.Event,
+		delivery.Payload,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Delivered,
+		delivery.NextAttemptAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = uint64(id)
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of events about reservations, tables and menu.
+const (
+	ReservationCreated   = "reservation.created"
+	ReservationUpdated   = "reservation.updated"
+	ReservationApproved  = "reservation.approved"
+	ReservationCancelled = "reservation.cancelled"
+	ReservationSeated    = "reservation.seated"
+	ReservationCompleted = "reservation.completed"
+	ReservationNoShow    = "reservation.no_show"
+	TableCreated         = "table.created"
+	TableUpdated         = "table.updated"
+	TableDeleted         = "table.deleted"
+	MenuItemCreated      = "menu_item.created"
+	MenuItemUpdated      = "menu_item.updated"
+	MenuItemDeleted      = "menu_item.deleted"
+)
+
+// Names is list of all supported events.
+var Names = []string{
+	ReservationCreated,
+	ReservationUpdated,
+	ReservationApproved,
+	ReservationCancelled,
+	ReservationSeated,
+	ReservationCompleted,
+	ReservationNoShow,
+	TableCreated,
+	TableUpdated,
+	TableDeleted,
+	MenuItemCreated,
+	MenuItemUpdated,
+	MenuItemDeleted,
+}
+
+// IsKnown returns true, when event name is supported.
+func IsKnown(name string) bool {
+	for _, known := range Names {
+		if name == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is published change.
+type Event struct {
+	// ID in format "<broker epoch>-<sequence>", used to resume stream.
+	ID   string
+	Type string
+	Data interface{}
+
+	sequence uint64
+}
+
+// Size of subscriber's buffer, slow subscribers are disconnected when it is full.
+const subscriberBuffer = 64
+
+// Broker delivers published events to subscribers inside the process.
+// It keeps last events in memory, so subscribers could resume after reconnect.
+type Broker struct {
+	mutex       sync.Mutex
+	epoch       string
+	sequence    uint64
+	history     []Event
+	historySize int
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker returns broker, which keeps specified number of last events.
+func NewBroker(historySize int) *Broker {
+	return &Broker{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish sends event to all subscribers.
+func (broker *Broker) Publish(eventType string, data interface{}) Event {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.sequence++
+
+	event := Event{
+		ID:       fmt.Sprintf("%s-%d", broker.epoch, broker.sequence),
+		Type:     eventType,
+		Data:     data,
+		sequence: broker.sequence,
+	}
+
+	broker.history = append(broker.history, event)
+	if len(broker.history) > broker.historySize {
+		broker.history = broker.history[len(broker.history)-broker.historySize:]
+	}
+
+	for subscriber := range broker.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// Subscriber is too slow, disconnect it, so client reconnects and resumes.
+			delete(broker.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return event
+}
+
+// Subscribe returns channel with new events and events, missed after lastEventID.
+// Missed events are empty, when lastEventID is empty or was issued by another broker.
+// Returned function should be called to unsubscribe.
+func (broker *Broker) Subscribe(lastEventID string) (<-chan Event, []Event, func()) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	subscriber := make(chan Event, subscriberBuffer)
+	broker.subscribers[subscriber] = struct{}{}
+
+	missed := make([]Event, 0)
+	if sequence, ok := broker.parseID(lastEventID); ok {
+		for _, event := range broker.history {
+			if event.sequence > sequence {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	unsubscribe := func() {
+		broker.mutex.Lock()
+		defer broker.mutex.Unlock()
+
+		if _, exists := broker.subscribers[subscriber]; exists {
+			delete(broker.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	return subscriber, missed, unsubscribe
+}
+
+func (broker *Broker) parseID(id string) (uint64, bool) {
+	parts := strings.SplitN(id, "-", 2)
+
+	if len(parts) != 2 || parts[0] != broker.epoch {
+		return 0, false
+	}
+
+	sequence, err := strconv.ParseUint(parts[1], 10, 64)
+
+	return sequence, err == nil
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishToSubscriber(t *testing.T) {
+	broker := NewBroker(10)
+
+	subscriber, missed, unsubscribe := broker.Subscribe("")
+	defer unsubscribe()
+
+	assert.Empty(t, missed)
+
+	published := broker.Publish(ReservationCreated, "payload")
+	received := <-subscriber
+
+	assert.Equal(t, published.ID, received.ID)
+	assert.Equal(t, ReservationCreated, received.Type)
+	assert.Equal(t, "payload", received.Data)
+}
+
+func TestResumeAfterLastEventID(t *testing.T) {
+	broker := NewBroker(10)
+
+	first := broker.Publish(TableCreated, 1)
+	broker.Publish(TableUpdated, 2)
+	broker.Publish(TableDeleted, 3)
+
+	_, missed, unsubscribe := broker.Subscribe(first.ID)
+	defer unsubscribe()
+
+	assert.Len(t, missed, 2)
+	assert.Equal(t, TableUpdated, missed[0].Type)
+	assert.Equal(t, TableDeleted, missed[1].Type)
+}
+
+func TestResumeIgnoresForeignID(t *testing.T) {
+	broker := NewBroker(10)
+	broker.Publish(TableCreated, 1)
+
+	_, missed, unsubscribe := broker.Subscribe("other-0")
+	defer unsubscribe()
+
+	assert.Empty(t, missed)
+}
+
+func TestHistoryIsLimited(t *testing.T) {
+	broker := NewBroker(2)
+
+	first := broker.Publish(TableCreated, 1)
+	broker.Publish(TableUpdated, 2)
+	broker.Publish(TableUpdated, 3)
+	broker.Publish(TableDeleted, 4)
+
+	_, missed, unsubscribe := broker.Subscribe(first.ID)
+	defer unsubscribe()
+
+	assert.Len(t, missed, 2)
+	assert.Equal(t, 3, missed[0].Data)
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	broker := NewBroker(1)
+
+	subscriber, _, unsubscribe := broker.Subscribe("")
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(TableUpdated, i)
+	}
+
+	count := 0
+	for range subscriber {
+		count++
+	}
+
+	assert.Equal(t, subscriberBuffer, count)
+}
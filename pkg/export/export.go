@@ -0,0 +1,242 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Format of time values in exported files.
+const timeFormat = "2006-01-02 15:04:05"
+
+// Writer writes rows of exported table one by one, so rows do not need to be kept in memory.
+type Writer interface {
+	// Write writes single row. Numbers are kept numeric, other values are written as text.
+	Write(row []interface{}) error
+	// Close finishes the file, no rows could be written after it.
+	Close() error
+}
+
+// IsValidFormat returns true for supported export format.
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+// ContentType returns MIME type of exported file.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns writer of specified format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("Unsupported export format %s", format)
+	}
+}
+
+// Returns CSV text, which spreadsheet applications do not evaluate as formula:
+// text, starting with formula characters, is prefixed with apostrophe.
+// Numbers, including phones in E.164 format like "+97333000000", are not formulas and kept as is.
+func escapeFormula(text string) string {
+	if text == "" || !strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return text
+	}
+
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return text
+	}
+
+	return "'" + text
+}
+
+// Returns text representation of the value.
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format(timeFormat)
+	case *time.Time:
+		if value == nil {
+			return ""
+		}
+		return value.Format(timeFormat)
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// Returns number representation of the value, if value is numeric.
+func formatNumber(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case int:
+		return strconv.Itoa(value), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case uint64:
+		return strconv.FormatUint(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row []interface{}) error {
+	record := make([]string, len(row))
+
+	for i, value := range row {
+		if number, ok := formatNumber(value); ok {
+			record[i] = number
+		} else {
+			record[i] = escapeFormula(formatValue(value))
+		}
+	}
+
+	return w.writer.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+// Static parts of minimal SpreadsheetML package with single worksheet.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`,
+	},
+}
+
+const sheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const sheetFooter = `</sheetData></worksheet>`
+
+// Writes worksheet rows with inline strings, so no shared strings table has to be built in memory.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := bufio.NewWriter(file)
+
+	if _, err := sheet.WriteString(sheetHeader); err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+func (w *xlsxWriter) Write(row []interface{}) error {
+	w.sheet.WriteString("<row>")
+
+	for _, value := range row {
+		if number, ok := formatNumber(value); ok {
+			w.sheet.WriteString(`<c><v>` + number + `</v></c>`)
+			continue
+		}
+
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+
+		if err := xml.EscapeText(w.sheet, []byte(formatValue(value))); err != nil {
+			return err
+		}
+
+		w.sheet.WriteString(`</t></is></c>`)
+	}
+
+	_, err := w.sheet.WriteString("</row>")
+
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := w.sheet.WriteString(sheetFooter); err != nil {
+		return err
+	}
+
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+
+	return w.archive.Close()
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+var rows = [][]interface{}{
+	{"id", "full_name", "phone", "time"},
+	{uint64(1), "Sara & Co", "+97333000000", time.Date(2019, 12, 1, 19, 30, 0, 0, time.UTC)},
+}
+
+func write(t *testing.T, format string) []byte {
+	buffer := bytes.Buffer{}
+
+	writer, err := NewWriter(format, &buffer)
+	assert.NoError(t, err)
+
+	for _, row := range rows {
+		assert.NoError(t, writer.Write(row))
+	}
+
+	assert.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	assert.Equal(t, "id,full_name,phone,time\n1,Sara & Co,+97333000000,2019-12-01 19:30:00\n", string(write(t, FormatCSV)))
+}
+
+func TestXLSX(t *testing.T) {
+	data := write(t, FormatXLSX)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	var sheet []byte
+
+	for _, file := range archive.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			reader, err := file.Open()
+			assert.NoError(t, err)
+
+			sheet, err = ioutil.ReadAll(reader)
+			assert.NoError(t, err)
+		}
+	}
+
+	assert.Len(t, archive.File, 5)
+	assert.Contains(t, string(sheet), `<c><v>1</v></c>`)
+	assert.Contains(t, string(sheet), `<t xml:space="preserve">Sara &amp; Co</t>`)
+	// Phone numbers should stay text and are not escaped, inline strings are never evaluated as formulas.
+	assert.Contains(t, string(sheet), `<t xml:space="preserve">+97333000000</t>`)
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1+1", "'+1+1"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"+97333000000", "+97333000000"},
+		{"-1.5", "-1.5"},
+		{"Sara = Co", "Sara = Co"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, escapeFormula(formatValue(test.value)))
+	}
+
+	// Numbers are written as numbers, not as text.
+	buffer := bytes.Buffer{}
+	writer, _ := NewWriter(FormatCSV, &buffer)
+	writer.Write([]interface{}{int64(-5), "-5", "-5-5"})
+	writer.Close()
+
+	assert.Equal(t, "-5,-5,'-5-5\n", buffer.String())
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ErrUnsupportedType is returned for files, which are not JPEG or PNG images.
+var ErrUnsupportedType = errors.New("Unsupported image type, should be JPEG or PNG")
+
+// Quality of JPEG thumbnails.
+const jpegQuality = 85
+
+// MaxPixels is limit of width multiplied by height of uploaded image.
+// Small compressed file could have large dimensions and take a lot of memory after decoding.
+var MaxPixels = 40 * 1000 * 1000
+
+// Image is validated uploaded image with its thumbnail.
+type Image struct {
+	ContentType string
+	// File extension with dot, e.g. ".jpg".
+	Extension string
+	Data      []byte
+	// Thumbnail in the same format as the image.
+	Thumbnail []byte
+}
+
+// Process validates type of uploaded image by its content and generates thumbnail,
+// which fits into square with specified size and keeps aspect ratio.
+func Process(data []byte, thumbnailSize int) (*Image, error) {
+	result := Image{Data: data, ContentType: http.DetectContentType(data)}
+
+	switch result.ContentType {
+	case "image/jpeg":
+		result.Extension = ".jpg"
+	case "image/png":
+		result.Extension = ".png"
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("Image could not be decoded")
+	}
+
+	// Dimensions are checked before pixels are decoded.
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("Image should not have more than %d pixels", MaxPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("Image could not be decoded")
+	}
+
+	thumbnail := Thumbnail(img, thumbnailSize)
+	buffer := bytes.Buffer{}
+
+	if result.ContentType == "image/png" {
+		err = png.Encode(&buffer, thumbnail)
+	} else {
+		err = jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.Thumbnail = buffer.Bytes()
+
+	return &result, nil
+}
+
+// Thumbnail scales image down to fit into square with specified size, keeping aspect ratio.
+// Every pixel of thumbnail is average of source pixels, it covers. Smaller images are only copied.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, count uint64
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+
+			thumbnail.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return thumbnail
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,95 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func encodedImage(t *testing.T, width, height int, encode func(*bytes.Buffer, image.Image) error) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 50, B: 50, A: 255})
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	assert.NoError(t, encode(&buffer, img))
+
+	return buffer.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	encodePNG := func(buffer *bytes.Buffer, img image.Image) error { return png.Encode(buffer, img) }
+	encodeJPEG := func(buffer *bytes.Buffer, img image.Image) error { return jpeg.Encode(buffer, img, nil) }
+
+	tests := []struct {
+		name          string
+		data          []byte
+		contentType   string
+		extension     string
+		width, height int
+	}{
+		{"landscape PNG", encodedImage(t, 400, 200, encodePNG), "image/png", ".png", 100, 50},
+		{"portrait JPEG", encodedImage(t, 150, 600, encodeJPEG), "image/jpeg", ".jpg", 25, 100},
+		{"small image", encodedImage(t, 40, 30, encodePNG), "image/png", ".png", 40, 30},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Process(test.data, 100)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, test.contentType, result.ContentType)
+			assert.Equal(t, test.extension, result.Extension)
+
+			thumbnail, _, err := image.Decode(bytes.NewReader(result.Thumbnail))
+			assert.NoError(t, err)
+			assert.Equal(t, image.Pt(test.width, test.height), thumbnail.Bounds().Size())
+		})
+	}
+}
+
+func TestProcessRejectsInvalidFiles(t *testing.T) {
+	_, err := Process([]byte("<svg></svg>"), 100)
+	assert.Equal(t, ErrUnsupportedType, err)
+
+	// PNG signature with broken content.
+	_, err = Process([]byte("\x89PNG\r\n\x1a\nbroken"), 100)
+	assert.Error(t, err)
+}
+
+func TestProcessRejectsTooManyPixels(t *testing.T) {
+	maxPixels := MaxPixels
+	defer func() { MaxPixels = maxPixels }()
+
+	MaxPixels = 100 * 100
+	encodePNG := func(buffer *bytes.Buffer, img image.Image) error { return png.Encode(buffer, img) }
+
+	_, err := Process(encodedImage(t, 100, 101, encodePNG), 50)
+	assert.EqualError(t, err, "Image should not have more than 10000 pixels")
+
+	_, err = Process(encodedImage(t, 100, 100, encodePNG), 50)
+	assert.NoError(t, err)
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(0, 0, color.Gray{Y: 255})
+	img.SetGray(1, 1, color.Gray{Y: 255})
+
+	r, _, _, _ := Thumbnail(img, 1).At(0, 0).RGBA()
+
+	assert.Equal(t, uint32(0x7f7f), r)
+}
@@ -0,0 +1,456 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/money"
+)
+
+// Kinds of imported rows.
+const (
+	KindTables     = "tables"
+	KindCategories = "categories"
+	KindMenu       = "menu"
+)
+
+// Formats of imported files.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Columns of CSV files for every kind, first row of the file should contain some of them.
+var columns = map[string][]string{
+	KindTables:     {"places", "description", "active"},
+	KindCategories: {"name", "order"},
+	KindMenu:       {"name", "description", "price", "category_id", "category", "image_url", "active"},
+}
+
+// MySQL error numbers, which are reported as errors of imported row.
+const (
+	mysqlDuplicateEntry      = 1062
+	mysqlNoReferencedRow     = 1452
+	mysqlNoReferencedRowLong = 1216
+)
+
+// IsValidFormat returns true for supported format of imported file.
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON
+}
+
+// IsValidKind returns true for supported kind of imported rows.
+func IsValidKind(kind string) bool {
+	_, ok := columns[kind]
+	return ok
+}
+
+// MenuItem is imported menu item, which could reference category by name instead of ID.
+type MenuItem struct {
+	db.MenuItem
+	// Name of existing or imported category.
+	Category string `json:"category"`
+}
+
+// RowError is validation error of imported row.
+//
+// swagger:model
+type RowError struct {
+	// Index of the row, starting from 1. Header of CSV file is not counted.
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// Report is result of import.
+//
+// swagger:model
+type Report struct {
+	Kind string `json:"kind"`
+	// Number of rows in the file.
+	Rows int `json:"rows"`
+	// Rows are imported only, when there are no errors in any of them.
+	Imported bool       `json:"imported"`
+	Errors   []RowError `json:"errors"`
+}
+
+// Import parses, validates and imports rows of specified kind in one transaction.
+func Import(DB *sqlx.DB, kind, format string, r io.Reader) (*Report, error) {
+	batch, rowErrors, err := Parse(kind, format, r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Apply(DB, kind, batch, rowErrors)
+}
+
+// Apply validates and imports parsed rows in one transaction, when there are no errors in any row.
+// Error is returned only for failures, which are not related to rows, e.g. lost DB connection.
+func Apply(DB *sqlx.DB, kind string, batch *db.ImportBatch, rowErrors []RowError) (*Report, error) {
+	report := Report{
+		Kind:   kind,
+		Rows:   len(batch.Tables) + len(batch.Categories) + len(batch.Menu),
+		Errors: rowErrors,
+	}
+
+	// Rows, which could not be parsed, are not validated.
+	failed := make(map[int]bool)
+
+	for _, rowErr := range rowErrors {
+		failed[rowErr.Row] = true
+	}
+
+	validationErrors, err := Validate(DB, batch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rowErr := range validationErrors {
+		if !failed[rowErr.Row] {
+			report.Errors = append(report.Errors, rowErr)
+		}
+	}
+
+	if len(report.Errors) != 0 {
+		return &report, nil
+	}
+
+	if err := batch.Import(DB); err != nil {
+		rowErr := &db.ImportRowError{}
+
+		if !errors.As(err, &rowErr) {
+			return nil, err
+		}
+
+		message, ok := rowErrorMessage(rowErr.Err)
+
+		if !ok {
+			return nil, err
+		}
+
+		report.Errors = append(report.Errors, RowError{Row: rowErr.Row, Error: message})
+
+		return &report, nil
+	}
+
+	report.Imported = true
+
+	return &report, nil
+}
+
+// Returns message of the error, which failed insert of the row. Known DB errors are replaced
+// with messages for the client. False is returned for errors, which are not caused by the row.
+func rowErrorMessage(err error) (string, bool) {
+	switch err := err.(type) {
+	case db.ValidationErrors:
+		return err.Error(), true
+	case *mysql.MySQLError:
+		switch err.Number {
+		case mysqlDuplicateEntry:
+			return "Row already exists", true
+		case mysqlNoReferencedRow, mysqlNoReferencedRowLong:
+			return "Referenced resource does not exist", true
+		}
+	}
+
+	return "", false
+}
+
+// Validate validates every row with the same rules as single rows are created with.
+// Orders of categories and categories of menu items are checked against existing categories.
+func Validate(DB *sqlx.DB, batch *db.ImportBatch) ([]RowError, error) {
+	rowErrors := make([]RowError, 0)
+
+	for i := range batch.Tables {
+		if err := batch.Tables[i].Validate(); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: err.Error()})
+		}
+	}
+
+	if len(batch.Categories) == 0 && len(batch.Menu) == 0 {
+		return rowErrors, nil
+	}
+
+	categories, err := db.MenuCategory.GetAll(db.MenuCategory{}, DB)
+
+	if err != nil {
+		return nil, err
+	}
+
+	existingIDs := make(map[uint64]bool)
+	existingNames := make(map[string]bool)
+	existingOrders := make(map[uint64]string)
+
+	for _, category := range *categories {
+		existingIDs[category.ID] = true
+		existingNames[category.Name] = true
+		existingOrders[category.Order] = category.Name
+	}
+
+	orders := make(map[uint64]int)
+	names := make(map[string]bool)
+
+	for i := range batch.Categories {
+		category := &batch.Categories[i]
+
+		if err := category.Validate(); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+
+		if row, ok := orders[category.Order]; ok {
+			errorMsg := fmt.Sprintf("Order %d is already used in row %d", category.Order, row)
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: errorMsg})
+			continue
+		}
+
+		if name, ok := existingOrders[category.Order]; ok {
+			errorMsg := fmt.Sprintf("Order %d is already used by category %s", category.Order, name)
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: errorMsg})
+			continue
+		}
+
+		orders[category.Order] = i + 1
+		names[category.Name] = true
+	}
+
+	for i := range batch.Menu {
+		menuItem := &batch.Menu[i]
+
+		if err := menuItem.Validate(); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: err.Error()})
+			continue
+		}
+
+		// Category name overrides category ID.
+		if i < len(batch.MenuCategories) && batch.MenuCategories[i] != "" {
+			if name := batch.MenuCategories[i]; !names[name] && !existingNames[name] {
+				rowErrors = append(rowErrors, RowError{Row: i + 1, Error: fmt.Sprintf("Category %s does not exist", name)})
+			}
+			continue
+		}
+
+		if menuItem.CategoryID == 0 {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: "Category should be specified"})
+			continue
+		}
+
+		if !existingIDs[menuItem.CategoryID] {
+			errorMsg := fmt.Sprintf("Invalid category id %d", menuItem.CategoryID)
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Error: errorMsg})
+		}
+	}
+
+	return rowErrors, nil
+}
+
+// Parse reads rows of specified kind from CSV or JSON array.
+// Rows, which could not be parsed, are returned as row errors.
+func Parse(kind, format string, r io.Reader) (*db.ImportBatch, []RowError, error) {
+	if !IsValidKind(kind) {
+		return nil, nil, fmt.Errorf("Unsupported kind %s, should be tables, categories or menu", kind)
+	}
+
+	switch format {
+	case FormatJSON:
+		batch, err := parseJSON(kind, r)
+		return batch, make([]RowError, 0), err
+	case FormatCSV:
+		return parseCSV(kind, r)
+	default:
+		return nil, nil, fmt.Errorf("Unsupported format %s, should be csv or json", format)
+	}
+}
+
+func parseJSON(kind string, r io.Reader) (*db.ImportBatch, error) {
+	batch := db.ImportBatch{}
+	decoder := json.NewDecoder(r)
+
+	var err error
+
+	switch kind {
+	case KindTables:
+		err = decoder.Decode(&batch.Tables)
+	case KindCategories:
+		err = decoder.Decode(&batch.Categories)
+	case KindMenu:
+		menu := make([]MenuItem, 0)
+		err = decoder.Decode(&menu)
+
+		for _, menuItem := range menu {
+			batch.Menu = append(batch.Menu, menuItem.MenuItem)
+			batch.MenuCategories = append(batch.MenuCategories, menuItem.Category)
+		}
+	}
+
+	if err != nil {
+		return nil, errors.New("Invalid JSON, should be array of rows")
+	}
+
+	return &batch, nil
+}
+
+// Row of CSV file with values accessible by column name.
+type csvRow struct {
+	header map[string]int
+	record []string
+}
+
+func (row csvRow) get(column string) string {
+	if i, ok := row.header[column]; ok && i < len(row.record) {
+		return strings.TrimSpace(row.record[i])
+	}
+
+	return ""
+}
+
+func (row csvRow) getInt(column string) (int64, error) {
+	value := row.get(column)
+
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s, should be integer", column)
+	}
+
+	return number, nil
+}
+
+// Active flag is true, when column is missing or empty.
+func (row csvRow) getBool(column string) (bool, error) {
+	value := row.get(column)
+
+	if value == "" {
+		return true, nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("Invalid %s, should be true or false", column)
+	}
+
+	return flag, nil
+}
+
+func parseCSV(kind string, r io.Reader) (*db.ImportBatch, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	names, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("Invalid CSV, first row should contain column names")
+	}
+
+	header := make(map[string]int)
+
+	for i, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if !contains(columns[kind], name) {
+			return nil, nil, fmt.Errorf("Unknown column %s, supported columns are %s", name, strings.Join(columns[kind], ", "))
+		}
+
+		header[name] = i
+	}
+
+	batch := db.ImportBatch{}
+	rowErrors := make([]RowError, 0)
+
+	for index := 1; ; index++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid CSV in row %d: %s", index, err)
+		}
+
+		// Row is appended even if it could not be parsed, so indexes of rows in batch match the file.
+		if err := appendRow(&batch, kind, csvRow{header: header, record: record}); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: index, Error: err.Error()})
+		}
+	}
+
+	return &batch, rowErrors, nil
+}
+
+func appendRow(batch *db.ImportBatch, kind string, row csvRow) error {
+	var err error
+
+	switch kind {
+	case KindTables:
+		table := db.Table{Description: row.get("description")}
+		table.Places, err = row.getInt("places")
+
+		if err == nil {
+			table.Active, err = row.getBool("active")
+		}
+
+		batch.Tables = append(batch.Tables, table)
+	case KindCategories:
+		category := db.MenuCategory{Name: row.get("name")}
+
+		var order int64
+		order, err = row.getInt("order")
+		category.Order = uint64(order)
+
+		if order < 0 {
+			err = errors.New("Invalid order, should not be negative")
+		}
+
+		batch.Categories = append(batch.Categories, category)
+	case KindMenu:
+		menuItem := db.MenuItem{
+			Name:        row.get("name"),
+			Description: row.get("description"),
+			ImageURL:    row.get("image_url"),
+		}
+
+		menuItem.Price, err = money.Parse(row.get("price"))
+
+		if err != nil {
+			err = fmt.Errorf("Invalid price, should be number with at most %d decimal places", money.Default.Decimals)
+		}
+
+		if err == nil {
+			var categoryID int64
+			categoryID, err = row.getInt("category_id")
+			menuItem.CategoryID = uint64(categoryID)
+		}
+
+		if err == nil {
+			menuItem.Active, err = row.getBool("active")
+		}
+
+		batch.Menu = append(batch.Menu, menuItem)
+		batch.MenuCategories = append(batch.MenuCategories, row.get("category"))
+	}
+
+	return err
+}
+
+func contains(list []string, value string) bool {
+	for _, tmp := range list {
+		if tmp == value {
+			return true
+		}
+	}
+
+	return false
+}
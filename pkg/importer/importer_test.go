@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestParseCSV(t *testing.T) {
+	file := "name,price,category,active\n" +
+		"Hummus,1.5,Starters,\n" +
+		"Falafel,abc,Starters,true\n" +
+		"Knafeh,2,,false\n"
+
+	batch, rowErrors, err := Parse(KindMenu, FormatCSV, strings.NewReader(file))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RowError{{Row: 2, Error: "Invalid price, should be number with at most 3 decimal places"}}, rowErrors)
+	assert.Len(t, batch.Menu, 3)
+	assert.Equal(t, "Hummus", batch.Menu[0].Name)
+	assert.Equal(t, money.Money(1500), batch.Menu[0].Price)
+	assert.True(t, batch.Menu[0].Active)
+	assert.False(t, batch.Menu[2].Active)
+	assert.Equal(t, []string{"Starters", "Starters", ""}, batch.MenuCategories)
+}
+
+func TestParseUnknownColumn(t *testing.T) {
+	_, _, err := Parse(KindTables, FormatCSV, strings.NewReader("places,seats\n4,4\n"))
+
+	assert.EqualError(t, err, "Unknown column seats, supported columns are places, description, active")
+}
+
+func TestParseJSON(t *testing.T) {
+	file := `[{"name": "Hummus", "price": 1.5, "category": "Starters"}, {"name": "Knafeh", "price": 2, "category_id": 3}]`
+
+	batch, rowErrors, err := Parse(KindMenu, FormatJSON, strings.NewReader(file))
+
+	assert.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Equal(t, uint64(3), batch.Menu[1].CategoryID)
+	assert.Equal(t, []string{"Starters", ""}, batch.MenuCategories)
+}
+
+func TestValidate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectQuery("^SELECT (.+) FROM categories").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "order", "parent_id"}).AddRow(3, "Soups", 5, nil))
+	mock.ExpectQuery("^SELECT (.+) FROM availability_windows").WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	batch := db.ImportBatch{
+		Tables:     []db.Table{{Places: 4}, {Places: 0}},
+		Categories: []db.MenuCategory{{Name: "Starters", Order: 1}, {Name: "Desserts", Order: 1}, {Name: "Salads", Order: 5}},
+		Menu: []db.MenuItem{
+			{Name: "Hummus", Price: money.Money(1500)},
+			{Name: "Lentil soup", Price: money.Money(1500), CategoryID: 3},
+			{Name: "Knafeh", Price: money.Money(2000), CategoryID: 7},
+			{Name: "Tabbouleh", Price: money.Money(1500)},
+			{Name: "Baklava", Price: money.Money(1500)},
+		},
+		MenuCategories: []string{"", "", "", "Starters", "Sweets"},
+	}
+
+	rowErrors, err := Validate(DB, &batch)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RowError{
+		{Row: 2, Error: "Places count should be more than 0"},
+		{Row: 2, Error: "Order 1 is already used in row 1"},
+		{Row: 3, Error: "Order 5 is already used by category Soups"},
+		{Row: 1, Error: "Category should be specified"},
+		{Row: 3, Error: "Invalid category id 7"},
+		{Row: 5, Error: "Category Sweets does not exist"},
+	}, rowErrors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRowErrorMessage(t *testing.T) {
+	tests := []struct {
+		err      error
+		message  string
+		rowError bool
+	}{
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'Starters' for key 'name'"}, "Row already exists", true},
+		{&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, "Referenced resource does not exist", true},
+		{db.ValidationErrors{{Field: "tags", Error: "Unknown tag spicy"}}, "Unknown tag spicy", true},
+		{&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, "", false},
+		{errors.New("driver: bad connection"), "", false},
+	}
+
+	for _, test := range tests {
+		message, ok := rowErrorMessage(test.err)
+
+		assert.Equal(t, test.message, message)
+		assert.Equal(t, test.rowError, ok)
+	}
+}
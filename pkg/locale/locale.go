@@ -0,0 +1,118 @@
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config contains languages, which content is available in.
+// Content in default language is stored in models itself, other languages are translations.
+type Config struct {
+	Default   string
+	Supported []string
+}
+
+// NewConfig returns config for comma separated list of languages.
+// Default language is always supported.
+func NewConfig(defaultLanguage, languages string) Config {
+	config := Config{Default: Normalize(defaultLanguage)}
+
+	if config.Default == "" {
+		config.Default = "en"
+	}
+
+	config.Supported = []string{config.Default}
+
+	for _, language := range strings.Split(languages, ",") {
+		if language = Normalize(language); language != "" && !config.IsSupported(language) {
+			config.Supported = append(config.Supported, language)
+		}
+	}
+
+	return config
+}
+
+// IsSupported checks, whether content could be translated to the language.
+func (config Config) IsSupported(language string) bool {
+	for _, supported := range config.Supported {
+		if supported == language {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Negotiate returns supported language, explicitly requested with "lang" parameter
+// or the most preferred one from Accept-Language header. Default language is used as fallback.
+func (config Config) Negotiate(lang, acceptLanguage string) string {
+	if lang = Normalize(lang); config.IsSupported(lang) {
+		return lang
+	}
+
+	for _, language := range ParseAcceptLanguage(acceptLanguage) {
+		if config.IsSupported(language) {
+			return language
+		}
+	}
+
+	return config.Default
+}
+
+// Normalize returns lowercase primary subtag of language tag, e.g. "ar" for "ar-BH".
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+
+	if i := strings.IndexAny(tag, "-_"); i != -1 {
+		tag = tag[:i]
+	}
+
+	return tag
+}
+
+// ParseAcceptLanguage returns languages of Accept-Language header, ordered by preference.
+// Languages with zero quality and wildcard are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	languages := make([]weighted, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		language := Normalize(params[0])
+		quality := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					value = 0
+				}
+				quality = value
+			}
+		}
+
+		if language == "" || language == "*" || quality <= 0 {
+			continue
+		}
+
+		languages = append(languages, weighted{language, quality})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	result := make([]string, len(languages))
+	for i, language := range languages {
+		result[i] = language.language
+	}
+
+	return result
+}
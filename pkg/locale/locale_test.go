@@ -0,0 +1,44 @@
+package locale
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	assert.Equal(t, Config{Default: "en", Supported: []string{"en", "ar"}}, NewConfig("en", "ar, EN ,"))
+	assert.Equal(t, Config{Default: "ar", Supported: []string{"ar", "en"}}, NewConfig("ar-BH", "en"))
+	assert.Equal(t, Config{Default: "en", Supported: []string{"en"}}, NewConfig("", ""))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"ar", "en", "fr"}, ParseAcceptLanguage("fr;q=0.5, ar-BH, en-US;q=0.8, de;q=0, *;q=0.1"))
+	assert.Equal(t, []string{}, ParseAcceptLanguage(""))
+}
+
+func TestNegotiate(t *testing.T) {
+	config := NewConfig("en", "en,ar")
+
+	tests := []struct {
+		name           string
+		lang           string
+		acceptLanguage string
+		expected       string
+	}{
+		{"parameter", "ar", "en", "ar"},
+		{"parameter with region", "AR-bh", "", "ar"},
+		{"unsupported parameter", "fr", "ar", "ar"},
+		{"header", "", "fr, ar;q=0.9, en;q=0.8", "ar"},
+		{"unsupported header", "", "fr, de", "en"},
+		{"nothing requested", "", "", "en"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, config.Negotiate(test.lang, test.acceptLanguage))
+		})
+	}
+}
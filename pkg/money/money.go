@@ -0,0 +1,172 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency describes currency of amounts.
+//
+// swagger:model
+type Currency struct {
+	// ISO 4217 code, e.g. "BHD".
+	Code string `json:"code"`
+	// Number of digits after decimal point, e.g. 3 for fils in dinar.
+	Decimals int `json:"decimals"`
+}
+
+// BHD is Bahraini Dinar, which is divided into 1000 fils.
+var BHD = Currency{Code: "BHD", Decimals: 3}
+
+// Default is currency of all amounts.
+var Default = BHD
+
+// Maximal number of digits before decimal point, so amount fits into int64.
+const maxIntegerDigits = 12
+
+// ParseError is returned for invalid amount.
+type ParseError struct {
+	Value string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("Invalid amount %s, should be number with at most %d decimal places", err.Value, Default.Decimals)
+}
+
+// Money is exact amount in minor units of default currency, e.g. fils.
+// In JSON it is number with fixed decimal places, e.g. 1.500, and in DB it is DECIMAL.
+type Money int64
+
+// Returns number of minor units in one major unit, e.g. 1000 fils in dinar.
+func scale() int64 {
+	return int64(math.Pow10(Default.Decimals))
+}
+
+// Parse parses decimal amount, e.g. "1.5" or "-0.250".
+// Error is returned for more decimal places, than default currency has.
+func Parse(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	invalid := &ParseError{Value: value}
+
+	negative := strings.HasPrefix(value, "-")
+	integer, fraction := strings.TrimPrefix(value, "-"), ""
+
+	if i := strings.Index(integer, "."); i != -1 {
+		integer, fraction = integer[:i], integer[i+1:]
+
+		if fraction == "" {
+			return 0, invalid
+		}
+	}
+
+	if integer == "" || len(integer) > maxIntegerDigits || len(fraction) > Default.Decimals {
+		return 0, invalid
+	}
+
+	for _, digits := range []string{integer, fraction} {
+		for _, digit := range digits {
+			if digit < '0' || digit > '9' {
+				return 0, invalid
+			}
+		}
+	}
+
+	fraction += strings.Repeat("0", Default.Decimals-len(fraction))
+
+	minor, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+
+	if negative {
+		minor = -minor
+	}
+
+	return Money(minor), nil
+}
+
+// FromFloat converts approximate amount to money, rounding it to minor units.
+func FromFloat(value float64) Money {
+	return Money(math.Round(value * float64(scale())))
+}
+
+// Mul returns amount multiplied by integer, e.g. price of several items.
+func (amount Money) Mul(count int64) Money {
+	return amount * Money(count)
+}
+
+// String returns amount with all decimal places of default currency, e.g. "1.500".
+func (amount Money) String() string {
+	sign := ""
+	minor := int64(amount)
+
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+
+	if Default.Decimals == 0 {
+		return sign + strconv.FormatInt(minor, 10)
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale(), Default.Decimals, minor%scale())
+}
+
+// Format returns amount with currency code, e.g. "1.500 BHD".
+func (amount Money) Format() string {
+	return amount.String() + " " + Default.Code
+}
+
+// MarshalJSON encodes amount as JSON number with fixed decimal places.
+func (amount Money) MarshalJSON() ([]byte, error) {
+	return []byte(amount.String()), nil
+}
+
+// UnmarshalJSON decodes amount from JSON number or string without rounding.
+func (amount *Money) UnmarshalJSON(data []byte) error {
+	value := string(data)
+
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+
+	parsed, err := Parse(value)
+	if err != nil {
+		return err
+	}
+
+	*amount = parsed
+
+	return nil
+}
+
+// Scan reads amount from DECIMAL column. FLOAT values are rounded to minor units.
+func (amount *Money) Scan(src interface{}) error {
+	var err error
+
+	switch value := src.(type) {
+	case nil:
+		*amount = 0
+	case []byte:
+		*amount, err = Parse(string(value))
+	case string:
+		*amount, err = Parse(value)
+	case int64:
+		*amount = Money(value * scale())
+	case float64:
+		*amount = FromFloat(value)
+	case float32:
+		*amount = FromFloat(float64(value))
+	default:
+		err = fmt.Errorf("Unsupported type %T of amount", src)
+	}
+
+	return err
+}
+
+// Value writes amount to DECIMAL column as exact decimal string.
+func (amount Money) Value() (driver.Value, error) {
+	return amount.String(), nil
+}
@@ -0,0 +1,96 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Money
+		valid    bool
+	}{
+		{"1.5", 1500, true},
+		{"0.001", 1, true},
+		{"12", 12000, true},
+		{"-0.250", -250, true},
+		{" 3.125 ", 3125, true},
+		{"1.2345", 0, false},
+		{"1.", 0, false},
+		{".5", 0, false},
+		{"1e3", 0, false},
+		{"1,5", 0, false},
+		{"", 0, false},
+		{"9999999999999", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			amount, err := Parse(test.value)
+
+			if test.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, amount)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "1.500", Money(1500).String())
+	assert.Equal(t, "0.005", Money(5).String())
+	assert.Equal(t, "-2.050", Money(-2050).String())
+	assert.Equal(t, "1.500 BHD", Money(1500).Format())
+}
+
+func TestArithmetic(t *testing.T) {
+	// 0.1 + 0.2 is exact in minor units.
+	sum := Money(100) + Money(200)
+	assert.Equal(t, "0.300", sum.String())
+	assert.Equal(t, Money(7500), Money(1250).Mul(6))
+	assert.Equal(t, Money(100), FromFloat(0.1))
+}
+
+func TestJSON(t *testing.T) {
+	item := struct {
+		Price Money `json:"price"`
+	}{}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": 1.25}`), &item))
+	assert.Equal(t, Money(1250), item.Price)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": "0.750"}`), &item))
+	assert.Equal(t, Money(750), item.Price)
+
+	data, err := json.Marshal(item)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"price":0.750}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"price": 1.2345}`), &item)
+	assert.IsType(t, &ParseError{}, err)
+}
+
+func TestScan(t *testing.T) {
+	var amount Money
+
+	assert.NoError(t, amount.Scan([]byte("2.500")))
+	assert.Equal(t, Money(2500), amount)
+
+	// FLOAT column before migration.
+	assert.NoError(t, amount.Scan(float64(float32(0.1))))
+	assert.Equal(t, Money(100), amount)
+
+	assert.NoError(t, amount.Scan(int64(3)))
+	assert.Equal(t, Money(3000), amount)
+
+	value, err := Money(1500).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.500", value)
+}
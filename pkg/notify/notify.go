@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/tools"
+)
+
+// Channel is delivery channel of notification.
+type Channel string
+
+const (
+	// ChannelEmail delivers notification by email.
+	ChannelEmail Channel = "email"
+	// ChannelSMS delivers notification by SMS.
+	ChannelSMS Channel = "sms"
+)
+
+// ErrUnsupportedChannel is returned, when notifier can not deliver message using channel.
+var ErrUnsupportedChannel = errors.New("Notification channel is not supported")
+
+// Message is notification for the client.
+type Message struct {
+	Channel Channel
+	// Email or phone of recipient, depends on channel.
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to clients.
+type Notifier interface {
+	Notify(message Message) error
+}
+
+// LogNotifier writes messages to log, used when no delivery provider is configured.
+type LogNotifier struct{}
+
+// Notify writes message to log.
+func (LogNotifier) Notify(message Message) error {
+	log.Printf("[notify] %s to %s: %s\n%s", message.Channel, message.To, message.Subject, message.Body)
+	return nil
+}
+
+// SMTPNotifier sends email messages using SMTP server.
+type SMTPNotifier struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+// Notify sends email message.
+func (notifier SMTPNotifier) Notify(message Message) error {
+	if message.Channel != ChannelEmail {
+		return ErrUnsupportedChannel
+	}
+
+	var auth smtp.Auth
+	if notifier.User != "" {
+		auth = smtp.PlainAuth("", notifier.User, notifier.Password, notifier.Host)
+	}
+
+	headers := []string{
+		fmt.Sprintf("From: %s", notifier.From),
+		fmt.Sprintf("To: %s", message.To),
+		fmt.Sprintf("Subject: %s", message.Subject),
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	address := fmt.Sprintf("%s:%s", notifier.Host, notifier.Port)
+
+	return smtp.SendMail(address, auth, notifier.From, []string{message.To}, []byte(body))
+}
+
+// FromEnv returns SMTP notifier, when SMTP_HOST is set, otherwise log notifier.
+func FromEnv() Notifier {
+	host := tools.GetEnv("SMTP_HOST", "")
+
+	if host == "" {
+		return LogNotifier{}
+	}
+
+	return SMTPNotifier{
+		Host:     host,
+		Port:     tools.GetEnv("SMTP_PORT", "587"),
+		User:     tools.GetEnv("SMTP_USER", ""),
+		Password: tools.GetEnv("SMTP_PASSWORD", ""),
+		From:     tools.GetEnv("SMTP_FROM", "no-reply@palestine-nights.com"),
+	}
+}
@@ -0,0 +1,47 @@
+package payment
+
+import (
+	"fmt"
+	"time"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/tools"
+)
+
+// Provider charges and refunds reservation deposits.
+type Provider interface {
+	// Charge starts payment of deposit.
+	// Returns payment reference and new deposit status: "paid" or "pending" for asynchronous payments.
+	Charge(deposit db.Deposit) (string, db.DepositStatus, error)
+	// Refund returns paid deposit to the client.
+	Refund(deposit db.Deposit) error
+}
+
+// FromEnv returns provider, configured with PAYMENT_PROVIDER environment variable.
+// Returns nil, when no provider is configured, so deposits could not be paid.
+func FromEnv() Provider {
+	switch tools.GetEnv("PAYMENT_PROVIDER", "") {
+	case "local":
+		return LocalProvider{}
+	default:
+		return nil
+	}
+}
+
+// LocalProvider is fake provider, which accepts every payment immediately.
+// Used only for development and tests, it should never be configured in production.
+type LocalProvider struct{}
+
+// Charge marks deposit as paid.
+func (LocalProvider) Charge(deposit db.Deposit) (string, db.DepositStatus, error) {
+	reference := fmt.Sprintf("local_%d_%d", deposit.ReservationID, time.Now().UnixNano())
+
+	return reference, db.DepositPaid, nil
+}
+
+// Refund always succeeds.
+func (LocalProvider) Refund(deposit db.Deposit) error {
+	return nil
+}
@@ -0,0 +1,321 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// Grouping of reservations in the report.
+const (
+	GroupDay   = "day"
+	GroupWeek  = "week"
+	GroupMonth = "month"
+	GroupState = "state"
+	GroupTable = "table"
+	GroupHour  = "hour"
+)
+
+// Groups is list of supported groupings.
+var Groups = []string{GroupDay, GroupWeek, GroupMonth, GroupState, GroupTable, GroupHour}
+
+// ContentType is MIME type of CSV reports.
+const ContentType = "text/csv; charset=utf-8"
+
+// Summary is aggregated statistics of reservations in one group.
+type Summary struct {
+	// Group key: start date of period, state, table ID or hour of the day.
+	Key          string `json:"key"`
+	Reservations int64  `json:"reservations"`
+	// Number of served guests: in reservations, which were seated or completed.
+	Covers int64 `json:"covers"`
+	// Average number of guests in served reservations.
+	AveragePartySize float64 `json:"average_party_size"`
+	Cancelled        int64   `json:"cancelled"`
+	NoShows          int64   `json:"no_shows"`
+	// Share of no-shows among reservations, which were due: seated, completed or no-show.
+	NoShowRate float64 `json:"no_show_rate"`
+	// Number of created or approved reservations, which are not served yet, and their guests.
+	Upcoming       int64 `json:"upcoming"`
+	UpcomingCovers int64 `json:"upcoming_covers"`
+
+	// Number of reservations, which were due.
+	due int64
+}
+
+// Utilization is share of opening time, when table was booked.
+type Utilization struct {
+	TableID      uint64 `json:"table_id"`
+	Reservations int64  `json:"reservations"`
+	// Booked time of the table within opening hours.
+	BookedHours float64 `json:"booked_hours"`
+	// Opening time of the restaurant in report range.
+	OpenHours   float64 `json:"open_hours"`
+	Utilization float64 `json:"utilization"`
+}
+
+// OpeningHours is daily opening time of the restaurant as offsets from midnight.
+type OpeningHours struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// ParseOpeningHours parses opening hours in "10:00-23:00" format.
+// Close time, earlier than open time, means closing after midnight.
+func ParseOpeningHours(value string) (OpeningHours, error) {
+	hours := OpeningHours{}
+
+	var openHour, openMinute, closeHour, closeMinute int
+
+	if _, err := fmt.Sscanf(value, "%d:%d-%d:%d", &openHour, &openMinute, &closeHour, &closeMinute); err != nil {
+		return hours, errors.New("Invalid opening hours, should be in 10:00-23:00 format")
+	}
+
+	hours.Open = time.Duration(openHour)*time.Hour + time.Duration(openMinute)*time.Minute
+	hours.Close = time.Duration(closeHour)*time.Hour + time.Duration(closeMinute)*time.Minute
+
+	if hours.Close <= hours.Open {
+		hours.Close += 24 * time.Hour
+	}
+
+	return hours, nil
+}
+
+// Returns opening and closing time for the day in restaurant location, which contains specified time.
+func (hours OpeningHours) on(day time.Time) (time.Time, time.Time) {
+	day = day.In(db.Location)
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, db.Location)
+
+	return midnight.Add(hours.Open), midnight.Add(hours.Close)
+}
+
+// IsValidGroup returns true for supported grouping.
+func IsValidGroup(group string) bool {
+	for _, tmp := range Groups {
+		if tmp == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns key of the group, which reservation belongs to. Days and hours are taken in restaurant location.
+func groupKey(group string, reservation db.Reservation) string {
+	t := reservation.Time.In(db.Location)
+
+	switch group {
+	case GroupWeek:
+		// Weeks start on Monday.
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02")
+	case GroupMonth:
+		return t.Format("2006-01")
+	case GroupState:
+		return string(reservation.State)
+	case GroupTable:
+		return strconv.FormatUint(reservation.TableID, 10)
+	case GroupHour:
+		return t.Format("15:00")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// Summarize aggregates reservations by specified group. Summaries are ordered by key.
+func Summarize(reservations []db.Reservation, group string) []Summary {
+	summaries := make(map[string]*Summary)
+
+	for _, reservation := range reservations {
+		key := groupKey(group, reservation)
+
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &Summary{Key: key}
+			summaries[key] = summary
+		}
+
+		summary.Reservations++
+
+		switch reservation.State {
+		case db.StateCancelled:
+			summary.Cancelled++
+		case db.StateNoShow:
+			summary.NoShows++
+			summary.due++
+		case db.StateSeated, db.StateCompleted:
+			summary.Covers += reservation.Guests
+			summary.due++
+		default:
+			summary.Upcoming++
+			summary.UpcomingCovers += reservation.Guests
+		}
+	}
+
+	result := make([]Summary, 0, len(summaries))
+
+	for _, summary := range summaries {
+		if served := summary.due - summary.NoShows; served > 0 {
+			summary.AveragePartySize = round(float64(summary.Covers) / float64(served))
+		}
+
+		if summary.due > 0 {
+			summary.NoShowRate = round(float64(summary.NoShows) / float64(summary.due))
+		}
+
+		result = append(result, *summary)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if group == GroupTable {
+			a, _ := strconv.ParseUint(result[i].Key, 10, 64)
+			b, _ := strconv.ParseUint(result[j].Key, 10, 64)
+			return a < b
+		}
+
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// Utilize calculates utilization of every table in [from, to) range.
+// Cancelled reservations and no-shows are not counted as booked time.
+func Utilize(reservations []db.Reservation, tables []db.Table, hours OpeningHours, from, to time.Time) []Utilization {
+	open := time.Duration(0)
+
+	// Opening time of the day before range could last after midnight.
+	first, _ := hours.on(from.AddDate(0, 0, -1))
+
+	for day := first.Add(-hours.Open); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start, finish := hours.on(day)
+		open += overlap(start, finish, from, to)
+	}
+
+	utilizations := make([]Utilization, 0, len(tables))
+
+	for _, table := range tables {
+		booked := time.Duration(0)
+		utilization := Utilization{TableID: table.ID, OpenHours: round(open.Hours())}
+
+		for _, reservation := range reservations {
+			if reservation.TableID != table.ID ||
+				reservation.State == db.StateCancelled || reservation.State == db.StateNoShow {
+				continue
+			}
+
+			if overlap(reservation.Time, reservation.GetStopTime(), from, to) == 0 {
+				continue
+			}
+
+			utilization.Reservations++
+
+			// Reservation could last after midnight, so opening time of previous day is checked too.
+			for _, day := range []time.Time{reservation.Time.AddDate(0, 0, -1), reservation.Time} {
+				start, finish := hours.on(day)
+				start, finish = clip(start, finish, from, to)
+				booked += overlap(reservation.Time, reservation.GetStopTime(), start, finish)
+			}
+		}
+
+		utilization.BookedHours = round(booked.Hours())
+
+		if open > 0 {
+			utilization.Utilization = round(float64(booked) / float64(open))
+		}
+
+		utilizations = append(utilizations, utilization)
+	}
+
+	return utilizations
+}
+
+// Returns intersection of [start, finish) with [from, to).
+func clip(start, finish, from, to time.Time) (time.Time, time.Time) {
+	if start.Before(from) {
+		start = from
+	}
+
+	if finish.After(to) {
+		finish = to
+	}
+
+	return start, finish
+}
+
+// Returns length of intersection of two time ranges.
+func overlap(start1, finish1, start2, finish2 time.Time) time.Duration {
+	start, finish := clip(start1, finish1, start2, finish2)
+
+	if !finish.After(start) {
+		return 0
+	}
+
+	return finish.Sub(start)
+}
+
+// Rounds value to 4 decimal places.
+func round(value float64) float64 {
+	return float64(int64(value*10000+0.5)) / 10000
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// WriteSummaries writes summaries in CSV format.
+func WriteSummaries(w io.Writer, summaries []Summary) error {
+	writer := csv.NewWriter(w)
+
+	writer.Write([]string{
+		"key", "reservations", "covers", "average_party_size", "cancelled", "no_shows", "no_show_rate",
+		"upcoming", "upcoming_covers",
+	})
+
+	for _, summary := range summaries {
+		writer.Write([]string{
+			summary.Key,
+			strconv.FormatInt(summary.Reservations, 10),
+			strconv.FormatInt(summary.Covers, 10),
+			formatFloat(summary.AveragePartySize),
+			strconv.FormatInt(summary.Cancelled, 10),
+			strconv.FormatInt(summary.NoShows, 10),
+			formatFloat(summary.NoShowRate),
+			strconv.FormatInt(summary.Upcoming, 10),
+			strconv.FormatInt(summary.UpcomingCovers, 10),
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteUtilizations writes table utilizations in CSV format.
+func WriteUtilizations(w io.Writer, utilizations []Utilization) error {
+	writer := csv.NewWriter(w)
+
+	writer.Write([]string{"table_id", "reservations", "booked_hours", "open_hours", "utilization"})
+
+	for _, utilization := range utilizations {
+		writer.Write([]string{
+			strconv.FormatUint(utilization.TableID, 10),
+			strconv.FormatInt(utilization.Reservations, 10),
+			formatFloat(utilization.BookedHours),
+			formatFloat(utilization.OpenHours),
+			formatFloat(utilization.Utilization),
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
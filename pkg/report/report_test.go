@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOpeningHours(t *testing.T) {
+	hours, err := ParseOpeningHours("10:00-23:30")
+	assert.NoError(t, err)
+	assert.Equal(t, OpeningHours{Open: 10 * time.Hour, Close: 23*time.Hour + 30*time.Minute}, hours)
+
+	hours, err = ParseOpeningHours("18:00-02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, OpeningHours{Open: 18 * time.Hour, Close: 26 * time.Hour}, hours)
+
+	_, err = ParseOpeningHours("always")
+	assert.Error(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	// 2019-12-02 is Monday.
+	monday := time.Date(2019, 12, 2, 19, 0, 0, 0, db.Location)
+	tuesday := monday.AddDate(0, 0, 1)
+
+	reservations := []db.Reservation{
+		{TableID: 1, Guests: 2, State: db.StateCompleted, Time: monday},
+		{TableID: 2, Guests: 4, State: db.StateNoShow, Time: monday},
+		{TableID: 1, Guests: 6, State: db.StateCompleted, Time: tuesday},
+		{TableID: 10, Guests: 3, State: db.StateCancelled, Time: tuesday.Add(time.Hour)},
+		// Guests of upcoming reservations are not counted as served.
+		{TableID: 2, Guests: 5, State: db.StateApproved, Time: tuesday.Add(time.Hour)},
+	}
+
+	days := Summarize(reservations, GroupDay)
+	assert.Equal(t, []Summary{
+		{Key: "2019-12-02", Reservations: 2, Covers: 2, AveragePartySize: 2, NoShows: 1, NoShowRate: 0.5, due: 2},
+		{Key: "2019-12-03", Reservations: 3, Covers: 6, AveragePartySize: 6, Cancelled: 1, Upcoming: 1, UpcomingCovers: 5, due: 1},
+	}, days)
+
+	weeks := Summarize(reservations, GroupWeek)
+	assert.Len(t, weeks, 1)
+	assert.Equal(t, "2019-12-02", weeks[0].Key)
+	assert.Equal(t, int64(8), weeks[0].Covers)
+	assert.Equal(t, 0.3333, weeks[0].NoShowRate)
+
+	tables := Summarize(reservations, GroupTable)
+	assert.Equal(t, "1", tables[0].Key)
+	assert.Equal(t, "2", tables[1].Key)
+	assert.Equal(t, "10", tables[2].Key)
+
+	hours := Summarize(reservations, GroupHour)
+	assert.Equal(t, "19:00", hours[0].Key)
+	assert.Equal(t, "20:00", hours[1].Key)
+}
+
+func TestUtilize(t *testing.T) {
+	hours := OpeningHours{Open: 18 * time.Hour, Close: 26 * time.Hour}
+	from := time.Date(2019, 12, 2, 0, 0, 0, 0, db.Location)
+	to := from.AddDate(0, 0, 1)
+
+	reservations := []db.Reservation{
+		{TableID: 1, State: db.StateCompleted, Time: from.Add(19 * time.Hour), Duration: 2 * time.Hour},
+		// Booked after midnight, but still within opening hours.
+		{TableID: 1, State: db.StateApproved, Time: from.Add(23 * time.Hour), Duration: 2 * time.Hour},
+		{TableID: 1, State: db.StateCancelled, Time: from.Add(21 * time.Hour), Duration: 2 * time.Hour},
+	}
+
+	tables := []db.Table{{ID: 1}, {ID: 2}}
+
+	utilizations := Utilize(reservations, tables, hours, from, to)
+
+	// Opening hours from previous day (00:00-02:00) and current day (18:00-24:00) are in range.
+	assert.Equal(t, []Utilization{
+		{TableID: 1, Reservations: 2, BookedHours: 3, OpenHours: 8, Utilization: 0.375},
+		{TableID: 2, OpenHours: 8},
+	}, utilizations)
+}
+
+func TestReportsUseRestaurantLocation(t *testing.T) {
+	// 22:30 UTC on Monday is 01:30 on Tuesday in Bahrain.
+	late := time.Date(2019, 12, 2, 22, 30, 0, 0, time.UTC)
+
+	reservations := []db.Reservation{{TableID: 1, Guests: 2, State: db.StateCompleted, Time: late, Duration: time.Hour}}
+
+	days := Summarize(reservations, GroupDay)
+	assert.Equal(t, "2019-12-03", days[0].Key)
+
+	hours := Summarize(reservations, GroupHour)
+	assert.Equal(t, "01:00", hours[0].Key)
+
+	// Range is passed in UTC, but opening hours are applied in restaurant location: 18:00-02:00 Bahrain time.
+	from := time.Date(2019, 12, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	utilizations := Utilize(reservations, []db.Table{{ID: 1}}, OpeningHours{Open: 18 * time.Hour, Close: 26 * time.Hour}, from, to)
+
+	// Open 15:00-23:00 UTC, reservation is booked 22:30-23:00 UTC within opening hours.
+	assert.Equal(t, []Utilization{{TableID: 1, Reservations: 1, BookedHours: 0.5, OpenHours: 8, Utilization: 0.0625}}, utilizations)
+}
+
+func TestWriteSummaries(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	err := WriteSummaries(&buffer, []Summary{{Key: "approved", Reservations: 3, Covers: 7, AveragePartySize: 2.3333}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key,reservations,covers,average_party_size,cancelled,no_shows,no_show_rate,upcoming,upcoming_covers\n"+
+		"approved,3,7,2.3333,0,0,0,0,0\n", buffer.String())
+}
@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/notify"
+	"github.com/palestine-nights/backend/pkg/tools"
+	"github.com/palestine-nights/backend/pkg/webhook"
+)
+
+// Config contains settings of reservation jobs.
+type Config struct {
+	// Period between job runs.
+	Interval time.Duration
+	// How long before reservation time reminder is sent.
+	ReminderBefore time.Duration
+	// How long created reservation waits for approval, before it is cancelled.
+	ApprovalWindow time.Duration
+}
+
+// ConfigFromEnv returns jobs configuration from environment variables.
+func ConfigFromEnv() Config {
+	return Config{
+		Interval:       tools.GetEnvDuration("SCHEDULER_INTERVAL", time.Minute),
+		ReminderBefore: tools.GetEnvDuration("REMINDER_BEFORE", 3*time.Hour),
+		ApprovalWindow: tools.GetEnvDuration("APPROVAL_WINDOW", 2*time.Hour),
+	}
+}
+
+// StateChanger changes reservation state the same way as API does,
+// so deposits are handled and subscribers are notified about the change.
+type StateChanger interface {
+	ChangeState(reservation *db.Reservation, state db.State) error
+}
+
+// ReservationJobs returns jobs, which send reminders and maintain reservation states.
+func ReservationJobs(DB *sqlx.DB, notifier notify.Notifier, states StateChanger, config Config) []Job {
+	return []Job{
+		{
+			Name:     "reservation-reminders",
+			Interval: config.Interval,
+			Run:      func() error { return sendReminders(DB, notifier, config.ReminderBefore) },
+		},
+		{
+			Name:     "reservation-approval-timeout",
+			Interval: config.Interval,
+			Run:      func() error { return cancelStale(DB, states, config.ApprovalWindow) },
+		},
+		{
+			Name:     "reservation-close",
+			Interval: config.Interval,
+			Run:      func() error { return closeFinished(DB, states) },
+		},
+	}
+}
+
+// WebhookJobs returns jobs, which retry failed webhook deliveries.
+func WebhookJobs(dispatcher *webhook.Dispatcher, config Config) []Job {
+	return []Job{
+		{
+			Name:     "webhook-retries",
+			Interval: config.Interval,
+			Run:      dispatcher.Retry,
+		},
+	}
+}
+
+// MenuJobs returns jobs, which maintain menu.
+func MenuJobs(DB *sqlx.DB, config Config) []Job {
+	return []Job{
+		{
+			Name:     "menu-price-changes",
+			Interval: config.Interval,
+			Run:      func() error { return applyPriceChanges(DB) },
+		},
+	}
+}
+
+// Sends reminders for upcoming approved reservations.
+func sendReminders(DB *sqlx.DB, notifier notify.Notifier, before time.Duration) error {
+	reservations, err := db.Reservation.GetForReminder(db.Reservation{}, DB, before)
+
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range *reservations {
+		message := notify.Message{
+			Channel: notify.ChannelEmail,
+			To:      reservation.Email,
+			Subject: "Reservation reminder",
+			Body: fmt.Sprintf("Dear %s, we are waiting for you and %d guest(s) on %s.",
+				reservation.FullName, reservation.Guests, reservation.Time.Format("Mon, 02 Jan 2006 15:04")),
+		}
+
+		// Failed reminder is retried on next run, other reservations are still reminded.
+		if err := notifier.Notify(message); err != nil {
+			log.Printf("[scheduler] reservation-reminders: could not remind reservation %d: %s", reservation.ID, err)
+			continue
+		}
+
+		if err := reservation.MarkReminded(DB); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Cancels reservations, which were not approved in time.
+func cancelStale(DB *sqlx.DB, states StateChanger, window time.Duration) error {
+	reservations, err := db.Reservation.GetStale(db.Reservation{}, DB, window)
+
+	if err != nil {
+		return err
+	}
+
+	return changeStates(states, reservations, db.StateCancelled)
+}
+
+// Completes reservations of seated guests and marks approved, but not seated ones as no-show.
+func closeFinished(DB *sqlx.DB, states StateChanger) error {
+	seated, err := db.Reservation.GetFinished(db.Reservation{}, DB, db.StateSeated)
+
+	if err != nil {
+		return err
+	}
+
+	if err := changeStates(states, seated, db.StateCompleted); err != nil {
+		return err
+	}
+
+	approved, err := db.Reservation.GetFinished(db.Reservation{}, DB, db.StateApproved)
+
+	if err != nil {
+		return err
+	}
+
+	return changeStates(states, approved, db.StateNoShow)
+}
+
+func changeStates(states StateChanger, reservations *[]db.Reservation, state db.State) error {
+	for i := range *reservations {
+		if err := states.ChangeState(&(*reservations)[i], state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Applies scheduled prices of menu items, which became effective.
+func applyPriceChanges(DB *sqlx.DB) error {
+	ids, err := db.MenuPrice.ApplyDue(db.MenuPrice{}, DB)
+
+	for _, id := range ids {
+		log.Printf("[scheduler] menu-price-changes: scheduled price of menu item %d is applied", id)
+	}
+
+	return err
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// Job is background task, which runs periodically.
+type Job struct {
+	// Unique name of the job, used as lock name.
+	Name string
+	// Period between job runs.
+	Interval time.Duration
+	// Function to run.
+	Run func() error
+}
+
+// Scheduler runs jobs in background.
+// Every job run is guarded by DB lock, so job runs only on one replica per interval.
+type Scheduler struct {
+	DB    *sqlx.DB
+	Owner string
+	Jobs  []Job
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns scheduler instance, identified by host name and process ID.
+func New(DB *sqlx.DB) *Scheduler {
+	hostname, err := os.Hostname()
+
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &Scheduler{
+		DB:    DB,
+		Owner: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		stop:  make(chan struct{}),
+	}
+}
+
+// Add registers job in scheduler.
+func (scheduler *Scheduler) Add(job Job) {
+	scheduler.Jobs = append(scheduler.Jobs, job)
+}
+
+// Start runs all registered jobs in background.
+func (scheduler *Scheduler) Start() {
+	for _, job := range scheduler.Jobs {
+		scheduler.wg.Add(1)
+		go scheduler.loop(job)
+	}
+}
+
+// Stop stops all jobs and waits for running ones to finish.
+func (scheduler *Scheduler) Stop() {
+	close(scheduler.stop)
+	scheduler.wg.Wait()
+}
+
+func (scheduler *Scheduler) loop(job Job) {
+	defer scheduler.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.runOnce(job)
+
+		select {
+		case <-ticker.C:
+		case <-scheduler.stop:
+			return
+		}
+	}
+}
+
+func (scheduler *Scheduler) runOnce(job Job) {
+	lock := db.JobLock{Name: job.Name, Owner: scheduler.Owner}
+
+	// Lock is not released after run, so job does not run more often than once per interval.
+	acquired, err := lock.Acquire(scheduler.DB, time.Now(), job.Interval)
+
+	if err != nil {
+		log.Printf("[scheduler] %s: could not acquire lock: %s", job.Name, err)
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	if err := job.Run(); err != nil {
+		log.Printf("[scheduler] %s: %s", job.Name, err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Storage keeps files in bucket of S3-compatible service, e.g. AWS S3 or MinIO.
+// Requests are signed with AWS Signature Version 4 and use path-style URLs.
+type S3Storage struct {
+	// Endpoint of the service, e.g. "https://s3.amazonaws.com".
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Base URL of public files, e.g. CDN. Object URL at endpoint is used, when empty.
+	PublicURL string
+	// Client to send requests, default client is used, when nil.
+	Client *http.Client
+}
+
+// Returns URL of object at endpoint.
+func (storage S3Storage) objectURL(key string) string {
+	return strings.TrimSuffix(storage.Endpoint, "/") + "/" + storage.Bucket + "/" + escapePath(key)
+}
+
+// Sends signed request to the object and checks response status.
+func (storage S3Storage) do(method, key string, body []byte, contentType string) error {
+	request, err := http.NewRequest(method, storage.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signV4(request, hex.EncodeToString(payloadHash[:]), storage.AccessKeyID, storage.SecretAccessKey,
+		storage.Region, "s3", time.Now())
+
+	client := storage.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("S3 %s %s failed with status %d: %s", method, key, response.StatusCode, message)
+	}
+
+	return nil
+}
+
+// Put uploads file to the bucket.
+func (storage S3Storage) Put(key string, r io.Reader, contentType string) (string, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if err := storage.do(http.MethodPut, key, body, contentType); err != nil {
+		return "", err
+	}
+
+	if storage.PublicURL != "" {
+		return strings.TrimSuffix(storage.PublicURL, "/") + "/" + escapePath(key), nil
+	}
+
+	return storage.objectURL(key), nil
+}
+
+// Delete removes file from the bucket.
+func (storage S3Storage) Delete(key string) error {
+	return storage.do(http.MethodDelete, key, nil, "")
+}
+
+// Key returns key of the object, URL of which starts with public URL or bucket URL at endpoint.
+func (storage S3Storage) Key(objectURL string) (string, bool) {
+	base := strings.TrimSuffix(storage.objectURL(""), "/") + "/"
+
+	if storage.PublicURL != "" {
+		base = strings.TrimSuffix(storage.PublicURL, "/") + "/"
+	}
+
+	if !strings.HasPrefix(objectURL, base) || objectURL == base {
+		return "", false
+	}
+
+	key, err := url.PathUnescape(strings.TrimPrefix(objectURL, base))
+	if err != nil {
+		return "", false
+	}
+
+	return key, true
+}
+
+// Escapes every segment of the path.
+func escapePath(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Signs request with AWS Signature Version 4, all request headers and host are signed.
+func signV4(request *http.Request, payloadHash, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := request.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalURI,
+		request.URL.Query().Encode(),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
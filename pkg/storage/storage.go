@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+import (
+	"github.com/palestine-nights/backend/pkg/tools"
+)
+
+// Storage keeps uploaded files and returns their public URLs.
+type Storage interface {
+	// Put stores file with specified key, e.g. "menu/1/image.jpg", and returns its URL.
+	Put(key string, r io.Reader, contentType string) (string, error)
+	// Delete removes file with specified key.
+	Delete(key string) error
+	// Key returns key of the file with specified URL.
+	// False is returned for URLs of files, which are not kept in the storage.
+	Key(url string) (string, bool)
+}
+
+// LocalStorage keeps files in local directory, which is served by the server itself.
+type LocalStorage struct {
+	// Directory to keep files in.
+	Dir string
+	// URL path, the directory is served at.
+	URLPrefix string
+}
+
+// Returns path of the file, key could not escape the directory.
+func (storage LocalStorage) path(key string) string {
+	return filepath.Join(storage.Dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes file to the directory.
+func (storage LocalStorage) Put(key string, r io.Reader, contentType string) (string, error) {
+	filename := storage.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(filename)
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(storage.URLPrefix, "/") + path.Clean("/"+key), nil
+}
+
+// Delete removes file from the directory, missing file is not an error.
+func (storage LocalStorage) Delete(key string) error {
+	if err := os.Remove(storage.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Key returns key of the file, URL of which starts with URL prefix.
+func (storage LocalStorage) Key(url string) (string, bool) {
+	prefix := strings.TrimSuffix(storage.URLPrefix, "/") + "/"
+
+	if !strings.HasPrefix(url, prefix) || url == prefix {
+		return "", false
+	}
+
+	return strings.TrimPrefix(url, prefix), true
+}
+
+// FromEnv returns S3 storage, when STORAGE is "s3", otherwise local storage.
+func FromEnv() Storage {
+	if tools.GetEnv("STORAGE", "local") == "s3" {
+		return S3Storage{
+			Endpoint:        tools.GetEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          tools.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:          tools.GetEnv("S3_BUCKET", ""),
+			AccessKeyID:     tools.GetEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: tools.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+			PublicURL:       tools.GetEnv("S3_PUBLIC_URL", ""),
+		}
+	}
+
+	return LocalStorage{
+		Dir:       tools.GetEnv("UPLOADS_DIR", "./uploads"),
+		URLPrefix: "/uploads",
+	}
+}
@@ -3,6 +3,8 @@ package tools
 import (
 	"os"
 	"regexp"
+	"strconv"
+	"time"
 )
 
 // GetEnv returns environment variable with ability to specify default value.
@@ -16,6 +18,48 @@ func GetEnv(key, fallback string) string {
 	return value
 }
 
+// GetEnvDuration returns environment variable parsed as duration (e.g. "90m", "2h").
+// Fallback is returned, when variable is not set or can not be parsed.
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(GetEnv(key, ""))
+
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// GetEnvInt returns environment variable parsed as integer.
+// Fallback is returned, when variable is not set or can not be parsed.
+func GetEnvInt(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(GetEnv(key, ""), 10, 64)
+
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// GetEnvLocation returns environment variable parsed as time zone name (e.g. "Asia/Bahrain").
+// Fallback is returned, when variable is not set or time zone is unknown.
+func GetEnvLocation(key string, fallback *time.Location) *time.Location {
+	value := GetEnv(key, "")
+
+	if value == "" {
+		return fallback
+	}
+
+	location, err := time.LoadLocation(value)
+
+	if err != nil {
+		return fallback
+	}
+
+	return location
+}
+
 // ValidateEmail validates email.
 func ValidateEmail(email string) bool {
 	result, err := regexp.MatchString(`.+@.+\..+`, email)
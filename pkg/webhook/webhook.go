@@ -0,0 +1,226 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+)
+
+// SignatureHeader is HTTP header with HMAC-SHA256 signature of the payload.
+const SignatureHeader = "X-Webhook-Signature"
+
+// Payload is JSON body, sent to webhook URL.
+type Payload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher delivers events to subscribed webhooks.
+// Every attempt is saved before it is made, failed attempts are retried by Retry.
+type Dispatcher struct {
+	DB     *sqlx.DB
+	Client *http.Client
+	// Maximum number of delivery attempts.
+	MaxAttempts int64
+	// Delay before the second attempt, doubled for every next one.
+	Backoff time.Duration
+	// Time, after which interrupted attempt is made again.
+	ClaimTimeout time.Duration
+}
+
+// Number of due attempts, made by one Retry call.
+const retryBatch = 100
+
+// NewDispatcher returns dispatcher with default settings.
+func NewDispatcher(DB *sqlx.DB) *Dispatcher {
+	return &Dispatcher{
+		DB:           DB,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:  5,
+		Backoff:      time.Minute,
+		ClaimTimeout: time.Minute,
+	}
+}
+
+// Sign returns hex encoded HMAC-SHA256 signature of body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newEventID() string {
+	id := make([]byte, 16)
+
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// Dispatch saves event delivery for all subscribed webhooks and makes the first attempt in background,
+// so caller is not blocked by slow receivers.
+func (dispatcher *Dispatcher) Dispatch(event string, data interface{}) {
+	webhooks, err := db.Webhook.GetSubscribed(db.Webhook{}, dispatcher.DB, event)
+
+	if err != nil {
+		log.Printf("[webhook] %s: could not load webhooks: %s", event, err)
+		return
+	}
+
+	payload := Payload{
+		ID:        newEventID(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		log.Printf("[webhook] %s: could not encode payload: %s", event, err)
+		return
+	}
+
+	for _, webhook := range *webhooks {
+		delivery := db.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       payload.ID,
+			Event:         payload.Event,
+			Payload:       string(body),
+			Attempt:       1,
+			NextAttemptAt: &payload.CreatedAt,
+		}
+
+		if err := delivery.Insert(dispatcher.DB); err != nil {
+			log.Printf("[webhook] %s: could not save delivery: %s", event, err)
+			continue
+		}
+
+		go func() {
+			if err := dispatcher.attempt(delivery); err != nil {
+				log.Printf("[webhook] %s: could not deliver: %s", event, err)
+			}
+		}()
+	}
+}
+
+// Retry makes delivery attempts, which are due: failed attempts after backoff and interrupted ones.
+func (dispatcher *Dispatcher) Retry() error {
+	deliveries, err := db.WebhookDelivery.GetDue(db.WebhookDelivery{}, dispatcher.DB, time.Now(), retryBatch)
+
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range *deliveries {
+		if err := dispatcher.attempt(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Makes scheduled delivery attempt and schedules the next one with exponential backoff, when it fails.
+func (dispatcher *Dispatcher) attempt(delivery db.WebhookDelivery) error {
+	now := time.Now()
+
+	claimed, err := delivery.Claim(dispatcher.DB, now, now.Add(dispatcher.ClaimTimeout))
+
+	if err != nil || !claimed {
+		return err
+	}
+
+	webhook, err := db.Webhook.Find(db.Webhook{}, dispatcher.DB, delivery.WebhookID)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !webhook.Active {
+		delivery.Error = "Webhook is inactive"
+		return delivery.SaveResult(dispatcher.DB)
+	}
+
+	payload := Payload{ID: delivery.EventID, Event: delivery.Event}
+	statusCode, err := dispatcher.post(*webhook, payload, []byte(delivery.Payload))
+
+	delivery.StatusCode = int64(statusCode)
+	delivery.Delivered = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	if err := delivery.SaveResult(dispatcher.DB); err != nil {
+		return err
+	}
+
+	if delivery.Delivered || delivery.Attempt >= dispatcher.MaxAttempts {
+		return nil
+	}
+
+	nextAttemptAt := time.Now().Add(dispatcher.Backoff << uint(delivery.Attempt-1))
+
+	next := db.WebhookDelivery{
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Attempt:       delivery.Attempt + 1,
+		NextAttemptAt: &nextAttemptAt,
+	}
+
+	return next.Insert(dispatcher.DB)
+}
+
+func (dispatcher *Dispatcher) post(webhook db.Webhook, payload Payload, body []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Event", payload.Event)
+	request.Header.Set("X-Webhook-Delivery", payload.ID)
+	request.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	response, err := dispatcher.Client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("Unexpected response status %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
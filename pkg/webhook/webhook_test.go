@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/palestine-nights/backend/pkg/db"
+	"github.com/palestine-nights/backend/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestPostSignsPayload(t *testing.T) {
+	body := []byte(`{"event":"reservation.created"}`)
+	hook := db.Webhook{ID: 1, Secret: "secret"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := ioutil.ReadAll(r.Body)
+
+		assert.Equal(t, body, received)
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, events.ReservationCreated, r.Header.Get("X-Webhook-Event"))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook.URL = server.URL
+	dispatcher := NewDispatcher(nil)
+
+	statusCode, err := dispatcher.post(hook, Payload{ID: "1", Event: events.ReservationCreated}, body)
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+}
+
+func TestPostFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(nil)
+	hook := db.Webhook{ID: 1, URL: server.URL, Secret: "secret"}
+
+	statusCode, err := dispatcher.post(hook, Payload{ID: "1", Event: events.MenuItemUpdated}, []byte(`{}`))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, statusCode)
+}
+
+func TestAttemptSchedulesRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	dispatcher := NewDispatcher(DB)
+	delivery := db.WebhookDelivery{ID: 3, WebhookID: 1, EventID: "1", Event: events.ReservationCreated, Payload: `{}`, Attempt: 2}
+
+	mock.ExpectExec("^UPDATE webhook_deliveries SET next_attempt_at = \\? WHERE id = \\? AND next_attempt_at <= \\?").
+		WithArgs(sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("^SELECT (.+) FROM webhooks WHERE id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "events", "active"}).AddRow(1, server.URL, "secret", "", true))
+	mock.ExpectExec("^UPDATE webhook_deliveries SET status_code").
+		WithArgs(http.StatusBadGateway, sqlmock.AnyArg(), false, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO webhook_deliveries").
+		WithArgs(1, "1", events.ReservationCreated, `{}`, 3, 0, "", false, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	assert.Nil(t, dispatcher.attempt(delivery))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptSkipsClaimedDelivery(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	DB := sqlx.NewDb(mockDB, "sqlmock")
+	defer DB.Close()
+
+	mock.ExpectExec("^UPDATE webhook_deliveries SET next_attempt_at").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Nil(t, NewDispatcher(DB).attempt(db.WebhookDelivery{ID: 3, WebhookID: 1, Attempt: 1}))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
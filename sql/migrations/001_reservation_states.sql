@@ -0,0 +1,12 @@
+-- Adds states of reservations after the visit, sent reminders and locks of background jobs.
+
+ALTER TABLE `reservations`
+  MODIFY state ENUM('created', 'approved', 'cancelled', 'seated', 'completed', 'no_show') NOT NULL DEFAULT 'created',
+  ADD reminder_sent_at DATETIME NULL AFTER duration;
+
+CREATE TABLE IF NOT EXISTS `job_locks` (
+  name         VARCHAR(63) NOT NULL,
+  owner        VARCHAR(255) NOT NULL,
+  locked_until DATETIME NOT NULL,
+  PRIMARY KEY (name)
+) ENGINE = InnoDB;
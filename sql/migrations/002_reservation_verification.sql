@@ -0,0 +1,20 @@
+-- Adds one-time codes to verify contacts of reservations.
+
+ALTER TABLE `reservations`
+  ADD verified_at DATETIME NULL AFTER duration;
+
+-- Existing reservations were created without verification, so they keep holding their tables.
+UPDATE `reservations` SET verified_at = created_at;
+
+CREATE TABLE IF NOT EXISTS `verifications` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  reservation_id INT UNSIGNED NOT NULL,
+  channel        ENUM('email', 'sms') NOT NULL,
+  code_hash      CHAR(64) NOT NULL,
+  attempts       TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  expires_at     DATETIME NOT NULL,
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (reservation_id)
+    REFERENCES reservations(id)
+) ENGINE = InnoDB;
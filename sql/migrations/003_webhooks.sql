@@ -0,0 +1,30 @@
+-- Adds webhooks and log of their deliveries.
+
+CREATE TABLE IF NOT EXISTS `webhooks` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  url         VARCHAR(2048) NOT NULL,
+  secret      VARCHAR(255) NOT NULL,
+  events      VARCHAR(1024) NOT NULL DEFAULT '',
+  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  webhook_id  INT UNSIGNED NOT NULL,
+  event_id    CHAR(32) NOT NULL,
+  event       VARCHAR(63) NOT NULL,
+  payload     MEDIUMTEXT NOT NULL,
+  attempt     TINYINT UNSIGNED NOT NULL,
+  status_code SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+  error       TEXT NOT NULL,
+  delivered   BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (webhook_id, created_at),
+  FOREIGN KEY (webhook_id)
+    REFERENCES webhooks(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
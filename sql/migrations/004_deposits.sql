@@ -0,0 +1,28 @@
+-- Adds deposits of reservations and rules, when deposit is required.
+
+CREATE TABLE IF NOT EXISTS `deposits` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  reservation_id INT UNSIGNED NOT NULL,
+  amount         DECIMAL(10, 3) NOT NULL,
+  status         ENUM('required', 'pending', 'paid', 'refunded', 'forfeited') NOT NULL DEFAULT 'required',
+  provider_ref   VARCHAR(255) NOT NULL DEFAULT '',
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE (reservation_id),
+  FOREIGN KEY (reservation_id)
+    REFERENCES reservations(id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `deposit_rules` (
+  id               INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  min_guests       TINYINT UNSIGNED NOT NULL,
+  weekdays         VARCHAR(31) NOT NULL DEFAULT '',
+  starts_on        DATE NULL,
+  ends_on          DATE NULL,
+  amount_per_guest DECIMAL(10, 3) NOT NULL,
+  active           BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
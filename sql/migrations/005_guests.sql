@@ -0,0 +1,20 @@
+-- Adds guest profiles, which reservations are linked to.
+-- Existing reservations are not linked, profiles are created for new reservations.
+
+CREATE TABLE IF NOT EXISTS `guests` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  full_name   VARCHAR(255) NOT NULL,
+  email       VARCHAR(63) NOT NULL DEFAULT '',
+  phone       VARCHAR(63) NOT NULL DEFAULT '',
+  notes       TEXT NOT NULL,
+  tags        VARCHAR(255) NOT NULL DEFAULT '',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (email),
+  INDEX (phone)
+) ENGINE = InnoDB;
+
+ALTER TABLE `reservations`
+  ADD guest_id INT UNSIGNED NULL AFTER table_id,
+  ADD FOREIGN KEY (guest_id) REFERENCES guests(id);
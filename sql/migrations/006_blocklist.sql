@@ -0,0 +1,20 @@
+-- Adds blocked contacts and reason, why deposit is required.
+
+CREATE TABLE IF NOT EXISTS `blocklist` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  phone       VARCHAR(63) NOT NULL DEFAULT '',
+  email       VARCHAR(63) NOT NULL DEFAULT '',
+  guest_id    INT UNSIGNED NULL,
+  reason      VARCHAR(255) NOT NULL DEFAULT '',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (phone),
+  INDEX (email),
+  FOREIGN KEY (guest_id)
+    REFERENCES guests(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+ALTER TABLE `deposits`
+  ADD reason VARCHAR(255) NOT NULL DEFAULT '' AFTER status;
@@ -0,0 +1,8 @@
+-- Adds occasion and special requests of reservations.
+
+ALTER TABLE `reservations`
+  ADD occasion ENUM('none', 'birthday', 'anniversary', 'business', 'date', 'other') NOT NULL DEFAULT 'none' AFTER duration,
+  ADD accessibility_needs VARCHAR(255) NOT NULL DEFAULT '' AFTER occasion,
+  ADD children TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER accessibility_needs,
+  ADD high_chairs TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER children,
+  ADD notes VARCHAR(500) NOT NULL DEFAULT '' AFTER high_chairs;
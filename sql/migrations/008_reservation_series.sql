@@ -0,0 +1,27 @@
+-- Adds recurring reservation series, which reservations are booked for.
+
+CREATE TABLE IF NOT EXISTS `reservation_series` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  table_id    INT UNSIGNED NOT NULL,
+  guest_id    INT UNSIGNED NULL,
+  guests      TINYINT UNSIGNED NOT NULL,
+  email       VARCHAR(63) NOT NULL,
+  phone       VARCHAR(63) NOT NULL,
+  full_name   VARCHAR(255) NOT NULL,
+  duration    BIGINT NOT NULL,
+  rule        VARCHAR(255) NOT NULL,
+  starts_at   DATETIME NOT NULL,
+  until       DATETIME NOT NULL,
+  state       ENUM('active', 'cancelled') NOT NULL DEFAULT 'active',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (table_id)
+    REFERENCES tables(id),
+  FOREIGN KEY (guest_id)
+    REFERENCES guests(id)
+) ENGINE = InnoDB;
+
+ALTER TABLE `reservations`
+  ADD series_id INT UNSIGNED NULL AFTER guest_id,
+  ADD FOREIGN KEY (series_id) REFERENCES reservation_series(id);
@@ -0,0 +1,29 @@
+-- Adds private events, which block chosen tables or whole venue.
+
+CREATE TABLE IF NOT EXISTS `private_events` (
+  id            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name          VARCHAR(255) NOT NULL,
+  contact_name  VARCHAR(255) NOT NULL,
+  contact_email VARCHAR(63) NOT NULL DEFAULT '',
+  contact_phone VARCHAR(63) NOT NULL DEFAULT '',
+  starts_at     DATETIME NOT NULL,
+  ends_at       DATETIME NOT NULL,
+  all_tables    BOOLEAN NOT NULL DEFAULT FALSE,
+  notes         TEXT NOT NULL,
+  created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (starts_at, ends_at)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `private_event_tables` (
+  event_id    INT UNSIGNED NOT NULL,
+  table_id    INT UNSIGNED NOT NULL,
+  PRIMARY KEY (event_id, table_id),
+  FOREIGN KEY (event_id)
+    REFERENCES private_events(id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (table_id)
+    REFERENCES tables(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
@@ -0,0 +1,32 @@
+-- Adds option groups and options of menu items.
+
+CREATE TABLE IF NOT EXISTS `option_groups` (
+  id           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id INT UNSIGNED NOT NULL,
+  name         VARCHAR(255) NOT NULL,
+  type         ENUM('single', 'multi') NOT NULL DEFAULT 'single',
+  min_choices  TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  max_choices  TINYINT UNSIGNED NOT NULL DEFAULT 1,
+  `order`      INT UNSIGNED NOT NULL DEFAULT 0,
+  created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `menu_options` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  group_id    INT UNSIGNED NOT NULL,
+  name        VARCHAR(255) NOT NULL,
+  price_delta FLOAT NOT NULL DEFAULT 0,
+  `order`     INT UNSIGNED NOT NULL DEFAULT 0,
+  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (group_id)
+    REFERENCES option_groups(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
@@ -0,0 +1,26 @@
+-- Adds translations of menu items and categories.
+
+CREATE TABLE IF NOT EXISTS `category_translations` (
+  category_id INT UNSIGNED NOT NULL,
+  lang        VARCHAR(8) NOT NULL,
+  name        VARCHAR(255) NOT NULL,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (category_id, lang),
+  FOREIGN KEY (category_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `menu_translations` (
+  menu_item_id INT UNSIGNED NOT NULL,
+  lang         VARCHAR(8) NOT NULL,
+  name         VARCHAR(255) NOT NULL,
+  description  TEXT NOT NULL,
+  created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (menu_item_id, lang),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
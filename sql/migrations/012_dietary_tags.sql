@@ -0,0 +1,38 @@
+-- Adds dietary tags with default allergens, diets and spice levels, and tags of menu items.
+
+CREATE TABLE IF NOT EXISTS `dietary_tags` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  code        VARCHAR(63) NOT NULL,
+  name        VARCHAR(255) NOT NULL,
+  kind        ENUM('allergen', 'diet', 'spice') NOT NULL,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE (code)
+) ENGINE = InnoDB;
+
+INSERT INTO `dietary_tags` (code, name, kind) VALUES
+  ('nuts', 'Contains nuts', 'allergen'),
+  ('gluten', 'Contains gluten', 'allergen'),
+  ('dairy', 'Contains dairy', 'allergen'),
+  ('eggs', 'Contains eggs', 'allergen'),
+  ('sesame', 'Contains sesame', 'allergen'),
+  ('vegan', 'Vegan', 'diet'),
+  ('vegetarian', 'Vegetarian', 'diet'),
+  ('halal', 'Halal', 'diet'),
+  ('gluten-free', 'Gluten-free', 'diet'),
+  ('mild', 'Mild', 'spice'),
+  ('spicy', 'Spicy', 'spice'),
+  ('extra-spicy', 'Extra spicy', 'spice');
+
+CREATE TABLE IF NOT EXISTS `menu_tags` (
+  menu_item_id INT UNSIGNED NOT NULL,
+  tag_id       INT UNSIGNED NOT NULL,
+  PRIMARY KEY (menu_item_id, tag_id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (tag_id)
+    REFERENCES dietary_tags(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
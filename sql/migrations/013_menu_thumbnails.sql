@@ -0,0 +1,4 @@
+-- Adds thumbnails of uploaded menu images.
+
+ALTER TABLE `menu`
+  ADD thumbnail_url VARCHAR(1024) NOT NULL DEFAULT '' AFTER image_url;
@@ -0,0 +1,6 @@
+-- Stores prices as exact decimal amounts instead of FLOAT.
+-- Existing values are rounded to 3 decimal places (fils).
+
+ALTER TABLE `menu` MODIFY price DECIMAL(10, 3) NOT NULL;
+
+ALTER TABLE `menu_options` MODIFY price_delta DECIMAL(10, 3) NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- Adds availability windows of menu items and categories.
+
+CREATE TABLE IF NOT EXISTS `availability_windows` (
+  id           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id INT UNSIGNED NULL,
+  category_id  INT UNSIGNED NULL,
+  weekdays     VARCHAR(31) NOT NULL DEFAULT '',
+  start_time   CHAR(5) NOT NULL DEFAULT '',
+  end_time     CHAR(5) NOT NULL DEFAULT '',
+  starts_on    DATE NULL,
+  ends_on      DATE NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (category_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
@@ -0,0 +1,18 @@
+-- Adds price history of menu items, current prices are effective since creation of items.
+
+CREATE TABLE IF NOT EXISTS `menu_prices` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id   INT UNSIGNED NOT NULL,
+  price          DECIMAL(10, 3) NOT NULL,
+  effective_from DATETIME NOT NULL,
+  applied        BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (applied, effective_from),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+INSERT INTO `menu_prices` (menu_item_id, price, effective_from, applied)
+  SELECT id, price, created_at, TRUE FROM `menu`;
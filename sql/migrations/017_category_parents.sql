@@ -0,0 +1,5 @@
+-- Adds optional parent categories for sub-sections.
+
+ALTER TABLE `categories`
+  ADD parent_id INT UNSIGNED NULL AFTER `order`,
+  ADD FOREIGN KEY (parent_id) REFERENCES categories(id);
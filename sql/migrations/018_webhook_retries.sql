@@ -0,0 +1,5 @@
+-- Keeps scheduled webhook delivery attempts, so retries survive restarts.
+
+ALTER TABLE `webhook_deliveries`
+  ADD next_attempt_at DATETIME NULL AFTER delivered,
+  ADD INDEX (next_attempt_at);
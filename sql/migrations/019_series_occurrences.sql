@@ -0,0 +1,8 @@
+-- Keeps occasion of recurring series and marks individually changed occurrences,
+-- which are not replaced, when series is updated.
+
+ALTER TABLE `reservation_series`
+  ADD occasion ENUM('none', 'birthday', 'anniversary', 'business', 'date', 'other') NOT NULL DEFAULT 'none' AFTER duration;
+
+ALTER TABLE `reservations`
+  ADD detached BOOLEAN NOT NULL DEFAULT FALSE AFTER state;
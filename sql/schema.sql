@@ -1,10 +1,29 @@
 CREATE DATABASE IF NOT EXISTS `restaurant`;
 USE `restaurant`;
 
+DROP TABLE IF EXISTS `verifications`;
+DROP TABLE IF EXISTS `deposits`;
+DROP TABLE IF EXISTS `deposit_rules`;
 DROP TABLE IF EXISTS `reservations`;
+DROP TABLE IF EXISTS `reservation_series`;
+DROP TABLE IF EXISTS `blocklist`;
+DROP TABLE IF EXISTS `private_event_tables`;
+DROP TABLE IF EXISTS `private_events`;
 DROP TABLE IF EXISTS `tables`;
+DROP TABLE IF EXISTS `guests`;
+DROP TABLE IF EXISTS `menu_prices`;
+DROP TABLE IF EXISTS `availability_windows`;
+DROP TABLE IF EXISTS `menu_tags`;
+DROP TABLE IF EXISTS `dietary_tags`;
+DROP TABLE IF EXISTS `menu_translations`;
+DROP TABLE IF EXISTS `category_translations`;
+DROP TABLE IF EXISTS `menu_options`;
+DROP TABLE IF EXISTS `option_groups`;
 DROP TABLE IF EXISTS `menu`;
 DROP TABLE IF EXISTS `categories`;
+DROP TABLE IF EXISTS `job_locks`;
+DROP TABLE IF EXISTS `webhook_deliveries`;
+DROP TABLE IF EXISTS `webhooks`;
 
 CREATE TABLE IF NOT EXISTS `tables` (
   id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
@@ -16,44 +35,347 @@ CREATE TABLE IF NOT EXISTS `tables` (
   PRIMARY KEY (id)
 ) ENGINE = InnoDB;
 
+CREATE TABLE IF NOT EXISTS `guests` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  full_name   VARCHAR(255) NOT NULL,
+  email       VARCHAR(63) NOT NULL DEFAULT '',
+  phone       VARCHAR(63) NOT NULL DEFAULT '',
+  notes       TEXT NOT NULL,
+  tags        VARCHAR(255) NOT NULL DEFAULT '',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (email),
+  INDEX (phone)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `blocklist` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  phone       VARCHAR(63) NOT NULL DEFAULT '',
+  email       VARCHAR(63) NOT NULL DEFAULT '',
+  guest_id    INT UNSIGNED NULL,
+  reason      VARCHAR(255) NOT NULL DEFAULT '',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (phone),
+  INDEX (email),
+  FOREIGN KEY (guest_id)
+    REFERENCES guests(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `reservation_series` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  table_id    INT UNSIGNED NOT NULL,
+  guest_id    INT UNSIGNED NULL,
+  guests      TINYINT UNSIGNED NOT NULL,
+  email       VARCHAR(63) NOT NULL,
+  phone       VARCHAR(63) NOT NULL,
+  full_name   VARCHAR(255) NOT NULL,
+  duration    BIGINT NOT NULL,
+  occasion    ENUM('none', 'birthday', 'anniversary', 'business', 'date', 'other') NOT NULL DEFAULT 'none',
+  rule        VARCHAR(255) NOT NULL,
+  starts_at   DATETIME NOT NULL,
+  until       DATETIME NOT NULL,
+  state       ENUM('active', 'cancelled') NOT NULL DEFAULT 'active',
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (table_id)
+    REFERENCES tables(id),
+  FOREIGN KEY (guest_id)
+    REFERENCES guests(id)
+) ENGINE = InnoDB;
+
 CREATE TABLE IF NOT EXISTS `reservations` (
   id           INT UNSIGNED NOT NULL AUTO_INCREMENT,
   table_id     INT UNSIGNED NOT NULL,
+  guest_id     INT UNSIGNED NULL,
+  series_id    INT UNSIGNED NULL,
   guests       TINYINT UNSIGNED NOT NULL,
   email        VARCHAR(63) NOT NULL,
   phone        VARCHAR(63) NOT NULL,
-  state        ENUM('created', 'approved', 'cancelled') NOT NULL DEFAULT 'created',
+  state        ENUM('created', 'approved', 'cancelled', 'seated', 'completed', 'no_show') NOT NULL DEFAULT 'created',
+  detached     BOOLEAN NOT NULL DEFAULT FALSE,
   full_name    VARCHAR(255) NOT NULL,
   time         DATETIME NOT NULL,
   duration     BIGINT,
+  occasion     ENUM('none', 'birthday', 'anniversary', 'business', 'date', 'other') NOT NULL DEFAULT 'none',
+  accessibility_needs VARCHAR(255) NOT NULL DEFAULT '',
+  children     TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  high_chairs  TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  notes        VARCHAR(500) NOT NULL DEFAULT '',
+  verified_at  DATETIME NULL,
+  reminder_sent_at DATETIME NULL,
   created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (id),
+  FOREIGN KEY (table_id)
+    REFERENCES tables(id),
+  FOREIGN KEY (guest_id)
+    REFERENCES guests(id),
+  FOREIGN KEY (series_id)
+    REFERENCES reservation_series(id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `verifications` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  reservation_id INT UNSIGNED NOT NULL,
+  channel        ENUM('email', 'sms') NOT NULL,
+  code_hash      CHAR(64) NOT NULL,
+  attempts       TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  expires_at     DATETIME NOT NULL,
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (reservation_id)
+    REFERENCES reservations(id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `deposits` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  reservation_id INT UNSIGNED NOT NULL,
+  amount         DECIMAL(10, 3) NOT NULL,
+  status         ENUM('required', 'pending', 'paid', 'refunded', 'forfeited') NOT NULL DEFAULT 'required',
+  reason         VARCHAR(255) NOT NULL DEFAULT '',
+  provider_ref   VARCHAR(255) NOT NULL DEFAULT '',
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE (reservation_id),
+  FOREIGN KEY (reservation_id)
+    REFERENCES reservations(id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `deposit_rules` (
+  id               INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  min_guests       TINYINT UNSIGNED NOT NULL,
+  weekdays         VARCHAR(31) NOT NULL DEFAULT '',
+  starts_on        DATE NULL,
+  ends_on          DATE NULL,
+  amount_per_guest DECIMAL(10, 3) NOT NULL,
+  active           BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `private_events` (
+  id            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name          VARCHAR(255) NOT NULL,
+  contact_name  VARCHAR(255) NOT NULL,
+  contact_email VARCHAR(63) NOT NULL DEFAULT '',
+  contact_phone VARCHAR(63) NOT NULL DEFAULT '',
+  starts_at     DATETIME NOT NULL,
+  ends_at       DATETIME NOT NULL,
+  all_tables    BOOLEAN NOT NULL DEFAULT FALSE,
+  notes         TEXT NOT NULL,
+  created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (starts_at, ends_at)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `private_event_tables` (
+  event_id    INT UNSIGNED NOT NULL,
+  table_id    INT UNSIGNED NOT NULL,
+  PRIMARY KEY (event_id, table_id),
+  FOREIGN KEY (event_id)
+    REFERENCES private_events(id)
+    ON DELETE CASCADE,
   FOREIGN KEY (table_id)
     REFERENCES tables(id)
+    ON DELETE CASCADE
 ) ENGINE = InnoDB;
 
 CREATE TABLE IF NOT EXISTS `categories` (
   id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
   name        VARCHAR(255) NOT NULL,
   `order`     INT UNSIGNED NOT NULL,
+  parent_id   INT UNSIGNED NULL,
   created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-  PRIMARY KEY (id)
+  PRIMARY KEY (id),
+  FOREIGN KEY (parent_id)
+    REFERENCES categories(id)
 ) ENGINE = InnoDB;
 
 CREATE TABLE IF NOT EXISTS `menu` (
+  id            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name          VARCHAR(255) NOT NULL,
+  description   TEXT NOT NULL,
+  price         DECIMAL(10, 3) NOT NULL,
+  category_id   INT UNSIGNED NOT NULL,
+  image_url     TEXT NOT NULL,
+  thumbnail_url VARCHAR(1024) NOT NULL DEFAULT '',
+  active        BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (category_id)
+    REFERENCES categories(id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `dietary_tags` (
   id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  code        VARCHAR(63) NOT NULL,
   name        VARCHAR(255) NOT NULL,
-  description TEXT NOT NULL,
-  price       FLOAT NOT NULL,
-  category_id INT UNSIGNED NOT NULL,
-  image_url   TEXT NOT NULL,
-  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  kind        ENUM('allergen', 'diet', 'spice') NOT NULL,
   created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (id),
+  UNIQUE (code)
+) ENGINE = InnoDB;
+
+INSERT INTO `dietary_tags` (code, name, kind) VALUES
+  ('nuts', 'Contains nuts', 'allergen'),
+  ('gluten', 'Contains gluten', 'allergen'),
+  ('dairy', 'Contains dairy', 'allergen'),
+  ('eggs', 'Contains eggs', 'allergen'),
+  ('sesame', 'Contains sesame', 'allergen'),
+  ('vegan', 'Vegan', 'diet'),
+  ('vegetarian', 'Vegetarian', 'diet'),
+  ('halal', 'Halal', 'diet'),
+  ('gluten-free', 'Gluten-free', 'diet'),
+  ('mild', 'Mild', 'spice'),
+  ('spicy', 'Spicy', 'spice'),
+  ('extra-spicy', 'Extra spicy', 'spice');
+
+CREATE TABLE IF NOT EXISTS `menu_tags` (
+  menu_item_id INT UNSIGNED NOT NULL,
+  tag_id       INT UNSIGNED NOT NULL,
+  PRIMARY KEY (menu_item_id, tag_id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (tag_id)
+    REFERENCES dietary_tags(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `menu_prices` (
+  id             INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id   INT UNSIGNED NOT NULL,
+  price          DECIMAL(10, 3) NOT NULL,
+  effective_from DATETIME NOT NULL,
+  applied        BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at     DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (applied, effective_from),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `availability_windows` (
+  id           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id INT UNSIGNED NULL,
+  category_id  INT UNSIGNED NULL,
+  weekdays     VARCHAR(31) NOT NULL DEFAULT '',
+  start_time   CHAR(5) NOT NULL DEFAULT '',
+  end_time     CHAR(5) NOT NULL DEFAULT '',
+  starts_on    DATE NULL,
+  ends_on      DATE NULL,
+  PRIMARY KEY (id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (category_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `category_translations` (
+  category_id INT UNSIGNED NOT NULL,
+  lang        VARCHAR(8) NOT NULL,
+  name        VARCHAR(255) NOT NULL,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (category_id, lang),
   FOREIGN KEY (category_id)
     REFERENCES categories(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `menu_translations` (
+  menu_item_id INT UNSIGNED NOT NULL,
+  lang         VARCHAR(8) NOT NULL,
+  name         VARCHAR(255) NOT NULL,
+  description  TEXT NOT NULL,
+  created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (menu_item_id, lang),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
 ) ENGINE = InnoDB;
 
+CREATE TABLE IF NOT EXISTS `option_groups` (
+  id           INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  menu_item_id INT UNSIGNED NOT NULL,
+  name         VARCHAR(255) NOT NULL,
+  type         ENUM('single', 'multi') NOT NULL DEFAULT 'single',
+  min_choices  TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  max_choices  TINYINT UNSIGNED NOT NULL DEFAULT 1,
+  `order`      INT UNSIGNED NOT NULL DEFAULT 0,
+  created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (menu_item_id)
+    REFERENCES menu(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `menu_options` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  group_id    INT UNSIGNED NOT NULL,
+  name        VARCHAR(255) NOT NULL,
+  price_delta DECIMAL(10, 3) NOT NULL DEFAULT 0,
+  `order`     INT UNSIGNED NOT NULL DEFAULT 0,
+  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  FOREIGN KEY (group_id)
+    REFERENCES option_groups(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;
+
+
+CREATE TABLE IF NOT EXISTS `job_locks` (
+  name         VARCHAR(63) NOT NULL,
+  owner        VARCHAR(255) NOT NULL,
+  locked_until DATETIME NOT NULL,
+  PRIMARY KEY (name)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `webhooks` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  url         VARCHAR(2048) NOT NULL,
+  secret      VARCHAR(255) NOT NULL,
+  events      VARCHAR(1024) NOT NULL DEFAULT '',
+  active      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+  id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  webhook_id  INT UNSIGNED NOT NULL,
+  event_id    CHAR(32) NOT NULL,
+  event       VARCHAR(63) NOT NULL,
+  payload     MEDIUMTEXT NOT NULL,
+  attempt     TINYINT UNSIGNED NOT NULL,
+  status_code SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+  error       TEXT NOT NULL,
+  delivered   BOOLEAN NOT NULL DEFAULT FALSE,
+  next_attempt_at DATETIME NULL,
+  created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX (webhook_id, created_at),
+  INDEX (next_attempt_at),
+  FOREIGN KEY (webhook_id)
+    REFERENCES webhooks(id)
+    ON DELETE CASCADE
+) ENGINE = InnoDB;